@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+const (
+	defaultCalloutStore  = "generated/callout/users.json"
+	defaultCalloutIssuer = "generated/callout/issuer.nk"
+)
+
+// runCallout implements `nats-demo callout`, the auth callout service.
+func runCallout(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: nats-demo callout <config|useradd|serve|oidc> [flags]")
+	}
+
+	switch args[0] {
+	case "config":
+		return calloutConfig(args[1:])
+	case "useradd":
+		return calloutUserAdd(args[1:])
+	case "serve":
+		return calloutServe(args[1:])
+	case "oidc":
+		return calloutOIDC(args[1:])
+	default:
+		return fmt.Errorf("unknown callout subcommand %q", args[0])
+	}
+}
+
+func calloutConfig(args []string) error {
+	fs := flag.NewFlagSet("callout config", flag.ContinueOnError)
+	config := fs.String("config", "generated/callout/server.conf", "server config to generate")
+	issuerFile := fs.String("issuer", defaultCalloutIssuer, "where to write the issuer seed")
+	port := fs.Int("port", 4229, "server port for the generated config")
+	authUser := fs.String("auth-user", "auth", "user the callout service connects as")
+	authPassword := fs.String("auth-password", "auth123", "password for the auth user")
+	accounts := fs.String("accounts", examples.DefaultCalloutAccount, "comma-separated accounts users can be placed in")
+	inventory := fs.String("inventory", examples.DefaultInventoryFile, "key inventory to record the issuer in")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	pair, err := examples.GenerateNKey(nkeys.PrefixByteAccount)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*issuerFile), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(*issuerFile, []byte(pair.Seed+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write issuer seed: %w", err)
+	}
+
+	err = examples.WriteAuthCalloutConfig(*config, examples.AuthCalloutConfig{
+		Port:            *port,
+		IssuerPublicKey: pair.PublicKey,
+		AuthUser:        *authUser,
+		AuthPassword:    *authPassword,
+		Accounts:        splitList(*accounts),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Issuer seed written to %s\n", *issuerFile)
+	fmt.Printf("✓ Server config written to %s\n", *config)
+	fmt.Printf("  Command: nats-server -c %s\n", *config)
+	fmt.Printf("  Then:    nats-demo callout serve --url nats://localhost:%d\n", *port)
+
+	return recordKeys(*inventory, []examples.GeneratedNKey{{Role: "callout issuer", Type: pair.Type, PublicKey: pair.PublicKey}}, *config)
+}
+
+func calloutUserAdd(args []string) error {
+	fs := flag.NewFlagSet("callout useradd", flag.ContinueOnError)
+	store := fs.String("store", defaultCalloutStore, "user store file")
+	role := fs.String("role", "", "role template: ADMIN, REQUESTOR or RESPONDER")
+	account := fs.String("account", "", "account to place the user in (default "+examples.DefaultCalloutAccount+")")
+	token := fs.Bool("token", false, "read a token instead of a password from stdin")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *role == "" {
+		return fmt.Errorf("usage: nats-demo callout useradd <name> --role ROLE [--account ACC] [--token] < secret")
+	}
+
+	secret, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && secret == "" {
+		return fmt.Errorf("failed to read secret from stdin: %w", err)
+	}
+	secret = strings.TrimSpace(secret)
+
+	users, err := examples.LoadCalloutUserStore(*store)
+	if err != nil {
+		return err
+	}
+	password := secret
+	if *token {
+		password = ""
+	} else {
+		secret = ""
+	}
+	if err := users.SetUser(fs.Arg(0), *role, *account, password, secret); err != nil {
+		return err
+	}
+	if err := users.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ %s (%s) saved to %s\n", fs.Arg(0), strings.ToUpper(*role), *store)
+	return nil
+}
+
+// calloutFlags are the connection and signing flags shared by the callout
+// services.
+type calloutFlags struct {
+	url, roles, issuer, authUser, authPassword *string
+	expires                                    *time.Duration
+}
+
+func addCalloutFlags(fs *flag.FlagSet) calloutFlags {
+	return calloutFlags{
+		url:          fs.String("url", "nats://localhost:4229", "server to serve auth requests for"),
+		roles:        fs.String("roles", "config/basic-auth.conf", "config whose role templates users map to"),
+		issuer:       fs.String("issuer-seed", "file:"+defaultCalloutIssuer, "issuer account seed or secret reference"),
+		authUser:     fs.String("auth-user", "auth", "auth user name"),
+		authPassword: fs.String("auth-password", "auth123", "auth user password or secret reference"),
+		expires:      fs.Duration("expires", 0, "lifetime of issued user JWTs (0 = no expiry)"),
+	}
+}
+
+// issuerKey resolves and parses the --issuer-seed flag.
+func (f calloutFlags) issuerKey() (nkeys.KeyPair, error) {
+	seed, err := examples.DefaultSecrets.Resolve(*f.issuer)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := nkeys.FromSeed([]byte(seed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issuer seed: %w", err)
+	}
+	return kp, nil
+}
+
+// serve connects as the auth user, starts the callout and blocks until
+// interrupted.
+func (f calloutFlags) serve(start func(nc *nats.Conn) error, what string) error {
+	password, err := examples.DefaultSecrets.Resolve(*f.authPassword)
+	if err != nil {
+		return err
+	}
+	nc, err := nats.Connect(*f.url, nats.UserInfo(*f.authUser, password), nats.Name("auth-callout"))
+	if err != nil {
+		return fmt.Errorf("failed to connect as %s: %w", *f.authUser, err)
+	}
+	defer nc.Close()
+
+	if err := start(nc); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Serving %s for %s with %s (Ctrl-C to stop)\n", examples.AuthCalloutSubject, *f.url, what)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	return nil
+}
+
+func printDecision(user string, err error) {
+	if err != nil {
+		fmt.Printf("✗ rejected %s: %v\n", user, err)
+	} else {
+		fmt.Printf("✓ accepted %s\n", user)
+	}
+}
+
+func calloutServe(args []string) error {
+	fs := flag.NewFlagSet("callout serve", flag.ContinueOnError)
+	store := fs.String("store", defaultCalloutStore, "user store file")
+	cf := addCalloutFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	issuer, err := cf.issuerKey()
+	if err != nil {
+		return err
+	}
+	users, err := examples.LoadCalloutUserStore(*store)
+	if err != nil {
+		return err
+	}
+	templates, err := examples.LoadRoleTemplates(*cf.roles)
+	if err != nil {
+		return err
+	}
+
+	callout := &examples.AuthCallout{
+		Issuer:    issuer,
+		Store:     users,
+		Roles:     templates,
+		ExpiresIn: *cf.expires,
+		Decisions: printDecision,
+	}
+	return cf.serve(func(nc *nats.Conn) error {
+		_, err := callout.Start(nc)
+		return err
+	}, fmt.Sprintf("%d users", len(users.Users)))
+}
+
+func calloutOIDC(args []string) error {
+	fs := flag.NewFlagSet("callout oidc", flag.ContinueOnError)
+	jwks := fs.String("jwks", "", "JWKS file or http(s) URL")
+	tokenIssuer := fs.String("token-issuer", "", "required iss claim (empty = any)")
+	audience := fs.String("audience", "nats", "required aud claim (empty = any)")
+	mappingFile := fs.String("mapping", "config/oidc-mapping.json", "tenant and group claim mapping")
+	cf := addCalloutFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *jwks == "" {
+		return fmt.Errorf("usage: nats-demo callout oidc --jwks <file|url> [--token-issuer iss] [--mapping file]")
+	}
+
+	issuer, err := cf.issuerKey()
+	if err != nil {
+		return err
+	}
+	keys, err := examples.LoadJWKS(*jwks)
+	if err != nil {
+		return err
+	}
+	mapping, err := examples.LoadOIDCMapping(*mappingFile)
+	if err != nil {
+		return err
+	}
+	templates, err := examples.LoadRoleTemplates(*cf.roles)
+	if err != nil {
+		return err
+	}
+
+	callout := &examples.OIDCCallout{
+		Issuer:      issuer,
+		JWKS:        keys,
+		TokenIssuer: *tokenIssuer,
+		Audience:    *audience,
+		Mapping:     mapping,
+		Roles:       templates,
+		ExpiresIn:   *cf.expires,
+		Decisions:   printDecision,
+	}
+	return cf.serve(func(nc *nats.Conn) error {
+		_, err := callout.Start(nc)
+		return err
+	}, fmt.Sprintf("%d JWKS key(s)", len(keys.Keys)))
+}
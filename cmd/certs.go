@@ -0,0 +1,194 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+)
+
+// runCerts implements `nats-demo certs`.
+func runCerts(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: nats-demo certs <init|ca|issue|revoke|ocsp> [flags]")
+	}
+
+	switch args[0] {
+	case "init":
+		return certsInit(args[1:])
+	case "ca":
+		return certsCA(args[1:])
+	case "issue":
+		return certsIssue(args[1:])
+	case "revoke":
+		return certsRevoke(args[1:])
+	case "ocsp":
+		return certsOCSP(args[1:])
+	default:
+		return fmt.Errorf("unknown certs subcommand %q", args[0])
+	}
+}
+
+// certsInit creates the certificates config/tls-mapping.conf expects.
+func certsInit(args []string) error {
+	fs := flag.NewFlagSet("certs init", flag.ContinueOnError)
+	dir := fs.String("dir", examples.DefaultTLSDir, "directory for the certificates")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if err := examples.GenerateDemoCerts(*dir); err != nil {
+		return err
+	}
+	fmt.Printf("✓ CA, server and client certificates written to %s\n", *dir)
+	return nil
+}
+
+func certsCA(args []string) error {
+	fs := flag.NewFlagSet("certs ca", flag.ContinueOnError)
+	dir := fs.String("dir", examples.DefaultTLSDir, "directory for the certificates")
+	cn := fs.String("cn", "NATS Demo CA", "CA common name")
+	days := fs.Int("days", 5*365, "validity in days")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ca, err := examples.NewCertAuthority(*cn, time.Duration(*days)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	if err := ca.WriteFiles(*dir, "ca"); err != nil {
+		return err
+	}
+	certFile, _ := examples.CertFiles(*dir, "ca")
+	fmt.Printf("✓ CA written to %s\n", certFile)
+	return nil
+}
+
+// certsIssue signs a server or client certificate with the CA in --dir.
+func certsIssue(args []string) error {
+	fs := flag.NewFlagSet("certs issue", flag.ContinueOnError)
+	dir := fs.String("dir", examples.DefaultTLSDir, "directory holding the CA and the new certificate")
+	name := fs.String("name", "", "file name prefix for the certificate and key")
+	cn := fs.String("cn", "", "common name (defaults to --name)")
+	emails := fs.String("email", "", "comma-separated email SANs")
+	dnsNames := fs.String("dns", "", "comma-separated DNS SANs")
+	ips := fs.String("ip", "", "comma-separated IP SANs")
+	client := fs.Bool("client", false, "issue a client certificate instead of a server certificate")
+	ocspURL := fs.String("ocsp", examples.DefaultOCSPURL, "OCSP responder for client certificates (empty for none)")
+	days := fs.Int("days", 365, "validity in days")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("usage: nats-demo certs issue --name <name> [--client] [--email ...] [--dns ...] [--ip ...]")
+	}
+	if *cn == "" {
+		*cn = *name
+	}
+
+	ca, err := examples.LoadCertificate(*dir, "ca")
+	if err != nil {
+		return err
+	}
+
+	req := examples.CertRequest{
+		CommonName:     *cn,
+		DNSNames:       splitList(*dnsNames),
+		EmailAddresses: splitList(*emails),
+		Client:         *client,
+		NotAfter:       time.Now().Add(time.Duration(*days) * 24 * time.Hour),
+	}
+	if *client && *ocspURL != "" {
+		req.OCSPServer = []string{*ocspURL}
+	}
+	for _, s := range splitList(*ips) {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address %q", s)
+		}
+		req.IPAddresses = append(req.IPAddresses, ip)
+	}
+
+	cert, err := ca.Issue(req)
+	if err != nil {
+		return err
+	}
+	if err := cert.WriteFiles(*dir, *name); err != nil {
+		return err
+	}
+
+	certFile, keyFile := examples.CertFiles(*dir, *name)
+	fmt.Printf("✓ Certificate written to %s (key %s)\n", certFile, keyFile)
+	if *client {
+		fmt.Printf("  verify_and_map identity: %s\n", examples.CertIdentity(cert.Cert))
+	}
+	return nil
+}
+
+// certsRevoke adds a certificate issued by the CA to the revocation list
+// the OCSP responder serves.
+func certsRevoke(args []string) error {
+	fs := flag.NewFlagSet("certs revoke", flag.ContinueOnError)
+	dir := fs.String("dir", examples.DefaultTLSDir, "directory holding the certificate")
+	name := fs.String("name", "", "file name prefix of the certificate to revoke")
+	reason := fs.String("reason", "", "reason recorded in the revocation list")
+	list := fs.String("list", examples.DefaultRevocationFile, "revocation list")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("usage: nats-demo certs revoke --name <name> [--reason text]")
+	}
+
+	cert, err := examples.LoadCertificate(*dir, *name)
+	if err != nil {
+		return err
+	}
+	revocations, err := examples.LoadRevocationList(*list)
+	if err != nil {
+		return err
+	}
+	entry := revocations.Revoke(cert.Cert, *reason)
+	if err := revocations.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Revoked %s (%s, serial %s) at %s\n", *name, entry.Subject, entry.Serial, entry.RevokedAt.Format(time.RFC3339))
+	return nil
+}
+
+// certsOCSP runs the OCSP responder for the CA in --dir until interrupted.
+func certsOCSP(args []string) error {
+	fs := flag.NewFlagSet("certs ocsp", flag.ContinueOnError)
+	dir := fs.String("dir", examples.DefaultTLSDir, "directory holding the CA")
+	listen := fs.String("listen", strings.TrimPrefix(examples.DefaultOCSPURL, "http://"), "address to listen on")
+	list := fs.String("list", examples.DefaultRevocationFile, "revocation list")
+	validity := fs.Duration("validity", time.Minute, "how long responses may be cached")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ca, err := examples.LoadCertificate(*dir, "ca")
+	if err != nil {
+		return err
+	}
+	responder := &examples.OCSPResponder{
+		CA:             ca,
+		RevocationFile: *list,
+		Validity:       *validity,
+		Decisions: func(serial, status string) {
+			fmt.Printf("[%s] %s: %s\n", time.Now().Format("15:04:05"), serial, status)
+		},
+	}
+
+	fmt.Printf("✓ OCSP responder for %s listening on %s (Ctrl+C to stop)\n", ca.Cert.Subject.CommonName, *listen)
+	if err := http.ListenAndServe(*listen, responder); err != nil {
+		return fmt.Errorf("OCSP responder failed: %w", err)
+	}
+	return nil
+}
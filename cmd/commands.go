@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// command is a non-interactive nats-demo subcommand.
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"callout", "callout <config|useradd|serve|oidc> [flags]", runCallout},
+	{"certs", "certs <init|ca|issue|revoke|ocsp> [flags]", runCerts},
+	{"fuzz", "fuzz [config] [--seed N] [--subjects N] [--patterns N] [-v]", runFuzz},
+	{"graph", "graph <config> [--format dot|mermaid|json]", runGraph},
+	{"jwt", "jwt <init|issue|revoke|push|export|activate> [flags]", runJWT},
+	{"keys", "keys <list|audit|retire> [flags]", runKeys},
+	{"monitor", "monitor [profiles...] [--kinds k,...] [--accounts a,...] [--users u,...]", runMonitor},
+	{"nkeys", "nkeys <gen|inspect|validate> [flags]", runNKeys},
+	{"sts", "sts <serve|request> [flags]", runSTS},
+	{"tenant", "tenant <init|add|remove|grant-import> [flags]", runTenant},
+	{"profiles", "profiles <list|check> [names...]", runProfiles},
+	{"secrets", "secrets <keyring-set|resolve> [flags]", runSecrets},
+	{"token", "token <hash|config> [flags]", runToken},
+	{"validate", "validate <config> [--sources ACCOUNT:SUBJECT,...]", runValidate},
+}
+
+// runCommand dispatches `nats-demo <command> ...` invocations.
+func runCommand(args []string) error {
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+	return fmt.Errorf("unknown command %q\n\n%s", args[0], commandUsage())
+}
+
+func commandUsage() string {
+	var b strings.Builder
+	b.WriteString("Usage:\n  nats-demo                  (interactive menu)\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "  nats-demo %s\n", c.usage)
+	}
+	return b.String()
+}
+
+// parseFlags parses args allowing flags after positional arguments, so both
+// `keys retire KEY --status revoked` and `keys retire --status revoked KEY`
+// work. Positional arguments are available from fs.Args() afterwards.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	return fs.Parse(positional)
+}
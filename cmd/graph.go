@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+)
+
+// runGraph implements `nats-demo graph`, rendering a config's accounts
+// with their exports and imports.
+func runGraph(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	format := fs.String("format", "dot", "output format: dot, mermaid or json")
+	out := fs.String("out", "", "write to this file instead of stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: nats-demo graph <config> [--format dot|mermaid|json] [--out file]")
+	}
+
+	g, err := examples.LoadAccountGraph(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch *format {
+	case "dot":
+		data = []byte(g.DOT())
+	case "mermaid":
+		data = []byte(g.Mermaid())
+	case "json":
+		if data, err = g.JSON(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q (want dot, mermaid or json)", *format)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(data)
+		return nil
+	}
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+	fmt.Printf("✓ Graph written to %s\n", *out)
+	return nil
+}
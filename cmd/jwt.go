@@ -0,0 +1,347 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+const defaultOperatorStore = "generated/jwt/operator.json"
+
+// runJWT implements `nats-demo jwt`, the operator-mode credential tooling.
+func runJWT(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: nats-demo jwt <init|issue|revoke|push|export|activate> [flags]")
+	}
+
+	switch args[0] {
+	case "init":
+		return jwtInit(args[1:])
+	case "issue":
+		return jwtIssue(args[1:])
+	case "revoke":
+		return jwtRevoke(args[1:])
+	case "push":
+		return jwtPush(args[1:])
+	case "export":
+		return jwtExport(args[1:])
+	case "activate":
+		return jwtActivate(args[1:])
+	default:
+		return fmt.Errorf("unknown jwt subcommand %q", args[0])
+	}
+}
+
+func jwtInit(args []string) error {
+	fs := flag.NewFlagSet("jwt init", flag.ContinueOnError)
+	store := fs.String("store", defaultOperatorStore, "operator store file")
+	accounts := fs.String("accounts", "A,B,C", "comma-separated accounts to create")
+	config := fs.String("config", "generated/jwt/server.conf", "server config to generate")
+	port := fs.Int("port", 4228, "server port for the generated config")
+	inventory := fs.String("inventory", examples.DefaultInventoryFile, "key inventory to record keys in")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	op, err := examples.NewJWTOperator("demo")
+	if err != nil {
+		return err
+	}
+	for _, name := range strings.Split(*accounts, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if _, err := op.AddAccount(name); err != nil {
+			return err
+		}
+	}
+
+	if err := op.Save(*store); err != nil {
+		return err
+	}
+	resolverDir := filepath.Join(filepath.Dir(*config), "resolver")
+	if err := op.WriteServerConfig(*config, *port, resolverDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Operator store written to %s\n", *store)
+	fmt.Printf("✓ Server config written to %s\n", *config)
+	fmt.Printf("  Command: nats-server -c %s\n", *config)
+
+	keys := []examples.GeneratedNKey{{Role: "operator", Type: nkeys.PrefixByteOperator, PublicKey: op.PublicKey}}
+	for _, name := range op.AccountNames() {
+		acc := op.Accounts[name]
+		keys = append(keys, examples.GeneratedNKey{Role: "account " + name, Type: nkeys.PrefixByteAccount, PublicKey: acc.PublicKey})
+	}
+	return recordKeys(*inventory, keys, *config, *store)
+}
+
+func jwtIssue(args []string) error {
+	fs := flag.NewFlagSet("jwt issue", flag.ContinueOnError)
+	store := fs.String("store", defaultOperatorStore, "operator store file")
+	account := fs.String("account", "", "account to issue the user under")
+	name := fs.String("name", "", "user name")
+	pub := fs.String("pub", ">", "comma-separated publish allow list")
+	sub := fs.String("sub", ">", "comma-separated subscribe allow list")
+	expires := fs.Duration("expires", 0, "credential lifetime, e.g. 1h (0 = no expiry)")
+	src := fs.String("src", "", "comma-separated CIDR blocks the user may connect from")
+	times := fs.String("times", "", "comma-separated daily windows, e.g. 09:00:00-17:00:00")
+	locale := fs.String("locale", "", "time zone for --times, e.g. Europe/Berlin (default: server local time)")
+	out := fs.String("creds", "", "write a .creds file here instead of stdout")
+	inventory := fs.String("inventory", examples.DefaultInventoryFile, "key inventory to record the user in")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *account == "" || *name == "" {
+		return fmt.Errorf("--account and --name are required")
+	}
+
+	windows, err := examples.ParseTimeRanges(*times)
+	if err != nil {
+		return err
+	}
+
+	op, err := examples.LoadJWTOperator(*store)
+	if err != nil {
+		return err
+	}
+
+	user, err := op.IssueUser(*account, examples.UserSpec{
+		Name:        *name,
+		Permissions: examples.PermissionsFor(splitList(*pub), splitList(*sub)),
+		ExpiresIn:   *expires,
+		Src:         splitList(*src),
+		Times:       windows,
+		Locale:      *locale,
+	})
+	if err != nil {
+		return err
+	}
+
+	creds, err := user.Creds()
+	if err != nil {
+		return err
+	}
+	keys := []examples.GeneratedNKey{{
+		Role:      fmt.Sprintf("%s@%s", user.Name, user.Account),
+		Type:      nkeys.PrefixByteUser,
+		PublicKey: user.PublicKey,
+	}}
+	if *out == "" {
+		// Record before printing so piped credentials are never untracked.
+		if err := examples.RecordGeneratedKeys(*inventory, keys); err != nil {
+			return err
+		}
+		os.Stdout.Write(creds)
+		return nil
+	}
+	if err := os.WriteFile(*out, creds, 0600); err != nil {
+		return fmt.Errorf("failed to write creds: %w", err)
+	}
+	fmt.Printf("✓ Issued %s (%s) in account %s\n", user.Name, user.PublicKey, user.Account)
+	fmt.Printf("✓ Credentials written to %s\n", *out)
+
+	return recordKeys(*inventory, keys)
+}
+
+func jwtRevoke(args []string) error {
+	fs := flag.NewFlagSet("jwt revoke", flag.ContinueOnError)
+	store := fs.String("store", defaultOperatorStore, "operator store file")
+	account := fs.String("account", "", "account the user belongs to")
+	user := fs.String("user", "", "user public key to revoke")
+	url := fs.String("url", "", "push the updated account JWT to this server")
+	inventory := fs.String("inventory", examples.DefaultInventoryFile, "key inventory to mark the user revoked in")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *account == "" || *user == "" {
+		return fmt.Errorf("--account and --user are required")
+	}
+
+	op, err := examples.LoadJWTOperator(*store)
+	if err != nil {
+		return err
+	}
+	if err := op.RevokeUser(*account, *user); err != nil {
+		return err
+	}
+	if err := op.Save(*store); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Revoked %s in account %s\n", *user, *account)
+
+	if inv, err := examples.LoadKeyInventory(*inventory); err != nil {
+		return err
+	} else if inv.Find(*user) != nil {
+		if err := inv.SetStatus(*user, examples.KeyRevoked, ""); err != nil {
+			return err
+		}
+		if err := inv.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Marked revoked in %s\n", *inventory)
+	}
+
+	if *url == "" {
+		fmt.Println("  Run `nats-demo jwt push` to apply the change to a running server")
+		return nil
+	}
+	return pushAccount(op, *account, *url)
+}
+
+func jwtPush(args []string) error {
+	fs := flag.NewFlagSet("jwt push", flag.ContinueOnError)
+	store := fs.String("store", defaultOperatorStore, "operator store file")
+	account := fs.String("account", "", "account to push")
+	url := fs.String("url", "nats://localhost:4228", "server to push to")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *account == "" {
+		return fmt.Errorf("--account is required")
+	}
+
+	op, err := examples.LoadJWTOperator(*store)
+	if err != nil {
+		return err
+	}
+	return pushAccount(op, *account, *url)
+}
+
+func jwtExport(args []string) error {
+	fs := flag.NewFlagSet("jwt export", flag.ContinueOnError)
+	store := fs.String("store", defaultOperatorStore, "operator store file")
+	account := fs.String("account", "", "exporting account")
+	subject := fs.String("subject", "", "subject to export")
+	kind := fs.String("type", "stream", "stream or service")
+	tokenReq := fs.Bool("token-required", false, "require importers to present an activation token")
+	url := fs.String("url", "", "push the updated account JWT to this server")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *account == "" || *subject == "" {
+		return fmt.Errorf("--account and --subject are required")
+	}
+
+	exportType, err := examples.ParseExportType(*kind)
+	if err != nil {
+		return err
+	}
+	op, err := examples.LoadJWTOperator(*store)
+	if err != nil {
+		return err
+	}
+	export := &jwt.Export{Subject: jwt.Subject(*subject), Type: exportType, TokenReq: *tokenReq}
+	if err := op.AddExport(*account, export); err != nil {
+		return err
+	}
+	if err := op.Save(*store); err != nil {
+		return err
+	}
+	visibility := "public"
+	if *tokenReq {
+		visibility = "token required"
+	}
+	fmt.Printf("✓ Account %s exports %s %s (%s)\n", *account, exportType, *subject, visibility)
+
+	if *url == "" {
+		return nil
+	}
+	return pushAccount(op, *account, *url)
+}
+
+func jwtActivate(args []string) error {
+	fs := flag.NewFlagSet("jwt activate", flag.ContinueOnError)
+	store := fs.String("store", defaultOperatorStore, "operator store file")
+	account := fs.String("account", "", "exporting account that signs the activation")
+	subject := fs.String("subject", "", "subject the importer may import")
+	kind := fs.String("type", "stream", "stream or service")
+	importer := fs.String("importer", "", "account the activation is issued to")
+	expires := fs.Duration("expires", 0, "activation lifetime, e.g. 24h (0 = no expiry)")
+	embed := fs.Bool("embed", false, "add the import, with the token, to the importer's account JWT")
+	local := fs.String("local", "", "with --embed: subject the import uses in the importer (default --subject)")
+	url := fs.String("url", "", "with --embed: push the importer's account JWT to this server")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *account == "" || *subject == "" || *importer == "" {
+		return fmt.Errorf("--account, --subject and --importer are required")
+	}
+
+	exportType, err := examples.ParseExportType(*kind)
+	if err != nil {
+		return err
+	}
+	op, err := examples.LoadJWTOperator(*store)
+	if err != nil {
+		return err
+	}
+	token, err := op.IssueActivation(*account, *subject, exportType, *importer, *expires)
+	if err != nil {
+		return err
+	}
+	if !*embed {
+		fmt.Println(token)
+		return nil
+	}
+
+	err = op.AddImport(*importer, examples.ImportSpec{
+		From:         *account,
+		Subject:      *subject,
+		Type:         exportType,
+		LocalSubject: *local,
+		Token:        token,
+	})
+	if err != nil {
+		return err
+	}
+	if err := op.Save(*store); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Account %s imports %s %s from %s with an activation %s\n",
+		*importer, exportType, *subject, *account, describeLifetime(*expires))
+
+	if *url == "" {
+		fmt.Println("  Run `nats-demo jwt push` to apply the change to a running server")
+		return nil
+	}
+	return pushAccount(op, *importer, *url)
+}
+
+func describeLifetime(d time.Duration) string {
+	if d == 0 {
+		return "that never expires"
+	}
+	return "expiring in " + d.String()
+}
+
+func pushAccount(op *examples.JWTOperator, account, url string) error {
+	nc, err := op.ConnectSystem(url)
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+
+	if err := op.PushAccount(nc, account); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Pushed account %s to %s\n", account, url)
+	return nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+)
+
+// runKeys implements `nats-demo keys`, the key inventory commands.
+func runKeys(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: nats-demo keys <list|audit|retire> [flags]")
+	}
+
+	switch args[0] {
+	case "list":
+		return keysList(args[1:])
+	case "audit":
+		return keysAudit(args[1:])
+	case "retire":
+		return keysRetire(args[1:])
+	default:
+		return fmt.Errorf("unknown keys subcommand %q", args[0])
+	}
+}
+
+func keysList(args []string) error {
+	fs := flag.NewFlagSet("keys list", flag.ContinueOnError)
+	inventory := fs.String("inventory", examples.DefaultInventoryFile, "key inventory file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	inv, err := examples.LoadKeyInventory(*inventory)
+	if err != nil {
+		return err
+	}
+	examples.PrintKeyInventory(inv)
+	return nil
+}
+
+func keysAudit(args []string) error {
+	fs := flag.NewFlagSet("keys audit", flag.ContinueOnError)
+	inventory := fs.String("inventory", examples.DefaultInventoryFile, "key inventory file")
+	maxAge := fs.String("max-age", "90d", "flag active keys older than this (e.g. 90d, 720h)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	age, err := examples.ParseKeyAge(*maxAge)
+	if err != nil {
+		return err
+	}
+
+	configs := fs.Args()
+	if len(configs) == 0 {
+		for _, pattern := range []string{"config/*.conf", "generated/*.conf", "generated/jwt/*.conf", defaultOperatorStore} {
+			matches, _ := filepath.Glob(pattern)
+			configs = append(configs, matches...)
+		}
+	}
+
+	inv, err := examples.LoadKeyInventory(*inventory)
+	if err != nil {
+		return err
+	}
+
+	findings, err := inv.Audit(age, configs)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range findings {
+		label := f.PublicKey
+		if f.Role != "" {
+			label = fmt.Sprintf("%s (%s)", f.PublicKey, f.Role)
+		}
+		fmt.Printf("✗ %s: %s\n", label, f.Problem)
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("%d audit findings across %d keys and %d configs", len(findings), len(inv.Keys), len(configs))
+	}
+
+	fmt.Printf("✓ %d keys and %d configs pass the audit\n", len(inv.Keys), len(configs))
+	return nil
+}
+
+func keysRetire(args []string) error {
+	fs := flag.NewFlagSet("keys retire", flag.ContinueOnError)
+	inventory := fs.String("inventory", examples.DefaultInventoryFile, "key inventory file")
+	status := fs.String("status", string(examples.KeyRotated), "rotated or revoked")
+	replacedBy := fs.String("replaced-by", "", "public key that replaces this one")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: nats-demo keys retire <public key> [--status rotated|revoked] [--replaced-by key]")
+	}
+
+	inv, err := examples.LoadKeyInventory(*inventory)
+	if err != nil {
+		return err
+	}
+	if err := inv.SetStatus(fs.Arg(0), examples.KeyStatus(*status), *replacedBy); err != nil {
+		return err
+	}
+	if err := inv.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Marked %s as %s\n", fs.Arg(0), *status)
+	return nil
+}
+
+// recordKeys adds freshly generated keys to the inventory.
+func recordKeys(inventory string, keys []examples.GeneratedNKey, configs ...string) error {
+	if err := examples.RecordGeneratedKeys(inventory, keys, configs...); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Recorded %d key(s) in %s\n", len(keys), inventory)
+	return nil
+}
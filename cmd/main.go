@@ -5,17 +5,29 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/anubhavg-icpl/nats-auth-demo/examples"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║      NATS Authorization & Multi-Tenancy Demo                 ║")
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
 
 	reader := bufio.NewReader(os.Stdin)
 
+	// stopMonitor is set while the auth event monitor is on.
+	var stopMonitor func()
+
 	for {
 		fmt.Println("\n┌────────────────────────────────────────────────────────────┐")
 		fmt.Println("│ Select a demo to run:                                      │")
@@ -70,11 +82,115 @@ func main() {
 		fmt.Println("│     - Config: config/nkeys-auth.conf                       │")
 		fmt.Println("│                                                            │")
 		fmt.Println("│  9. Generate NKeys                                         │")
-		fmt.Println("│     - Generate new NKey pairs for users                    │")
+		fmt.Println("│     - Operator, account, user, server, cluster, curve keys │")
 		fmt.Println("│     - Demonstrates signature verification                  │")
 		fmt.Println("│                                                            │")
 		fmt.Println("│  10. Run All Demos                                         │")
 		fmt.Println("│                                                            │")
+		fmt.Println("│  11. User Revocation & Credential Expiry (JWT)             │")
+		fmt.Println("│     - Revoke a user and push the account JWT               │")
+		fmt.Println("│     - Users issued with an expiry (exp claim)              │")
+		fmt.Println("│     - Server: embedded (operator mode)                     │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  12. Encrypted Payloads with XKeys                         │")
+		fmt.Println("│     - Curve keys seal request and response payloads        │")
+		fmt.Println("│     - Admin on '>' sees only ciphertext                    │")
+		fmt.Println("│     - Server: localhost:4227                               │")
+		fmt.Println("│     - Config: config/nkeys-auth.conf                       │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  13. Secret Providers                                      │")
+		fmt.Println("│     - Seeds and passwords resolved by reference            │")
+		fmt.Println("│     - env:, file:, keyring: and vault: providers           │")
+		fmt.Println("│     - Server: embedded                                     │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  14. Auth Callout                                          │")
+		fmt.Println("│     - Users and tokens checked against a JSON user store   │")
+		fmt.Println("│     - Signed user JWTs with role template permissions      │")
+		fmt.Println("│     - Server: embedded                                     │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  15. OIDC Login via Auth Callout                           │")
+		fmt.Println("│     - OIDC tokens verified against a local JWKS            │")
+		fmt.Println("│     - Tenant claim picks account A/B/C, groups pick roles  │")
+		fmt.Println("│     - Server: embedded (config/accounts.conf)              │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  16. Short-Lived Credentials (STS)                         │")
+		fmt.Println("│     - Workloads trade their identity for scoped creds      │")
+		fmt.Println("│     - Policy limits subjects and lifetime                  │")
+		fmt.Println("│     - Server: embedded (operator mode)                     │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  17. Token Authentication                                  │")
+		fmt.Println("│     - Single shared token stored as a bcrypt hash          │")
+		fmt.Println("│     - Token rotation with reconnecting clients             │")
+		fmt.Println("│     - Server: embedded (config/token-auth.conf)            │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  18. TLS Certificate Mapping                               │")
+		fmt.Println("│     - Mutual TLS with verify_and_map, no passwords         │")
+		fmt.Println("│     - Cert email/DNS SAN picks the user and permissions    │")
+		fmt.Println("│     - Server: embedded (config/tls-mapping.conf)           │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  19. TLS Certificate Revocation (OCSP)                     │")
+		fmt.Println("│     - Local OCSP responder backed by a revocation list     │")
+		fmt.Println("│     - Revoked client cert rejected, fresh cert accepted    │")
+		fmt.Println("│     - Server: embedded (config/tls-ocsp.conf)              │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  20. Connection, Subscription & Payload Limits             │")
+		fmt.Println("│     - Per-account limits in config, per-user limits in JWTs│")
+		fmt.Println("│     - Reports which limit tripped                          │")
+		fmt.Println("│     - Server: embedded (config/limits.conf, operator mode) │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  21. Allowed Connection Types                              │")
+		fmt.Println("│     - Standard, WebSocket and MQTT listeners               │")
+		fmt.Println("│     - Users locked to their channel                        │")
+		fmt.Println("│     - Server: embedded (config/connection-types.conf)      │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  22. Source-IP & Time-Window Restrictions                  │")
+		fmt.Println("│     - User JWTs limited by src CIDRs (loopback aliases)    │")
+		fmt.Println("│     - Access windows that open and close during the demo   │")
+		fmt.Println("│     - Server: embedded (operator mode)                     │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  23. Cross-Account Service Latency                         │")
+		fmt.Println("│     - Latency sampling on A's pubq.> and q.b exports       │")
+		fmt.Println("│     - Collector reporting percentiles per importer         │")
+		fmt.Println("│     - Server: embedded (generated config)                  │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  24. Activation Tokens for Private Exports                 │")
+		fmt.Println("│     - Token-required stream and service exports in JWTs    │")
+		fmt.Println("│     - Activations embedded in the importer's imports       │")
+		fmt.Println("│     - Server: embedded (operator mode)                     │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  25. Tenant Provisioning with Live Reload                  │")
+		fmt.Println("│     - Account D added, granted and removed mid-session     │")
+		fmt.Println("│     - Invalid changes refused, other clients stay connected│")
+		fmt.Println("│     - Server: embedded (config/accounts.conf, reloaded)    │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  26. Hot-Reload Permission Changes                         │")
+		fmt.Println("│     - limited user subscribes, then permissions narrow     │")
+		fmt.Println("│     - Kept, filtered and dropped subscriptions reported    │")
+		fmt.Println("│     - Server: embedded (config/allow-deny.conf, reloaded)  │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  27. Streamed and Chunked Service Responses                │")
+		fmt.Println("│     - response_type stream/chunked vs singleton exports    │")
+		fmt.Println("│     - Client collects until a sentinel or idle timeout     │")
+		fmt.Println("│     - Server: embedded (generated config)                  │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  28. Subject Mappings and Weighted Transforms              │")
+		fmt.Println("│     - Wildcard rearrangement, canary split, partitions     │")
+		fmt.Println("│     - Publish permissions apply to the mapped subject      │")
+		fmt.Println("│     - Server: embedded (generated config)                  │")
+		fmt.Println("│                                                            │")
+		fmt.Println("│  29. Account Isolation Fuzzer                              │")
+		fmt.Println("│     - Random subjects across every account pair            │")
+		fmt.Println("│     - Flags deliveries no export/import path allows        │")
+		fmt.Println("│     - Server: embedded (config/accounts.conf)              │")
+		fmt.Println("│                                                            │")
+		monitorState := "off"
+		if stopMonitor != nil {
+			monitorState = "on"
+		}
+		fmt.Printf("│  m. Auth event monitor: %-35s│\n", monitorState)
+		fmt.Println("│     - Shows connects and auth failures from sys- profiles  │")
+		fmt.Println("│     - Covers demos 1-7 only (fixed ports 4222-4226)        │")
+		fmt.Println("│                                                            │")
 		fmt.Println("│  0. Exit                                                   │")
 		fmt.Println("└────────────────────────────────────────────────────────────┘")
 		fmt.Print("\nEnter your choice: ")
@@ -215,6 +331,7 @@ func main() {
 			fmt.Print("Press Enter when ready...")
 			reader.ReadString('\n')
 			examples.DemoNKeysAuth()
+			examples.DemoNKeysEncryptedRequest()
 
 			fmt.Println("\n" + strings.Repeat("=", 64))
 			fmt.Println("Demo 9: Generate NKeys")
@@ -225,7 +342,94 @@ func main() {
 			fmt.Println("All demos completed!")
 			fmt.Println(strings.Repeat("=", 64))
 
+		case "11":
+			examples.DemoUserRevocation()
+
+		case "12":
+			fmt.Println("\n⚠️  Make sure NATS server is running with config/nkeys-auth.conf")
+			fmt.Println("   Command: nats-server -c config/nkeys-auth.conf")
+			fmt.Print("\nPress Enter to continue...")
+			reader.ReadString('\n')
+			examples.DemoNKeysEncryptedRequest()
+
+		case "13":
+			examples.DemoSecretProviders()
+
+		case "14":
+			examples.DemoAuthCallout()
+
+		case "15":
+			examples.DemoOIDCCallout()
+
+		case "16":
+			examples.DemoSTSCredentials()
+
+		case "17":
+			examples.DemoTokenAuth()
+
+		case "18":
+			examples.DemoTLSMapping()
+
+		case "19":
+			examples.DemoTLSRevocation()
+
+		case "20":
+			examples.DemoLimits()
+
+		case "21":
+			examples.DemoConnectionTypes()
+
+		case "22":
+			examples.DemoUserRestrictions()
+
+		case "23":
+			examples.DemoServiceLatency()
+
+		case "24":
+			examples.DemoActivationTokens()
+
+		case "25":
+			examples.DemoTenantProvisioning()
+
+		case "26":
+			examples.DemoPermissionReload()
+
+		case "27":
+			examples.DemoStreamedResponses()
+
+		case "28":
+			examples.DemoSubjectMappings()
+
+		case "29":
+			examples.DemoAccountIsolationFuzz()
+
+		case "m":
+			if stopMonitor != nil {
+				stopMonitor()
+				stopMonitor = nil
+				fmt.Println("\n✓ Auth event monitor stopped")
+				break
+			}
+			profiles, err := systemProfiles(examples.DefaultProfilesFile, nil)
+			if err != nil {
+				fmt.Printf("\n❌ %v\n", err)
+				break
+			}
+			print := func(profile string, e examples.AuthEvent) {
+				fmt.Printf("  🔔 [%s] %s\n", profile, e)
+			}
+			stop, watched := startMonitors(profiles, examples.AuthEventFilter{}, 250*time.Millisecond, print, false)
+			if watched == 0 {
+				fmt.Println("\n❌ No server with a system account is running")
+				break
+			}
+			stopMonitor = stop
+			fmt.Printf("\n✓ Auth event monitor watching %d server(s); events print as demos run\n", watched)
+
 		case "0":
+			if stopMonitor != nil {
+				stopMonitor()
+			}
 			fmt.Println("\nExiting... Goodbye!")
 			return
 
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+	"github.com/nats-io/nats.go"
+)
+
+// systemProfilePrefix marks the profiles of system account users.
+const systemProfilePrefix = "sys-"
+
+// runMonitor implements `nats-demo monitor`, a live stream of connects,
+// disconnects and authentication failures from one or more servers.
+func runMonitor(args []string) error {
+	fs := flag.NewFlagSet("monitor", flag.ContinueOnError)
+	file := fs.String("file", examples.DefaultProfilesFile, "profiles file")
+	kinds := fs.String("kinds", "", "comma-separated event kinds: connect, disconnect, auth_error (default all)")
+	accounts := fs.String("accounts", "", "comma-separated accounts to show (default all)")
+	users := fs.String("users", "", "comma-separated users to show (default all)")
+	poll := fs.Duration("poll", time.Second, "how often to poll global account connections (0 = never)")
+	asJSON := fs.Bool("json", false, "print one JSON object per event")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	filter := examples.AuthEventFilter{
+		Kinds:    splitList(*kinds),
+		Accounts: splitList(*accounts),
+		Users:    splitList(*users),
+	}
+	for _, kind := range filter.Kinds {
+		switch kind {
+		case examples.EventConnect, examples.EventDisconnect, examples.EventAuthError:
+		default:
+			return fmt.Errorf("unknown event kind %q (want connect, disconnect or auth_error)", kind)
+		}
+	}
+
+	profiles, err := systemProfiles(*file, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	print := func(profile string, e examples.AuthEvent) {
+		if *asJSON {
+			data, _ := json.Marshal(struct {
+				Profile string `json:"profile"`
+				examples.AuthEvent
+			}{profile, e})
+			fmt.Println(string(data))
+			return
+		}
+		fmt.Printf("[%s] %s\n", profile, e)
+	}
+	stop, watched := startMonitors(profiles, filter, *poll, print, true)
+	if watched == 0 {
+		return fmt.Errorf("no server could be monitored")
+	}
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Watching %d server(s); press Ctrl-C to stop\n", watched)
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	<-sig
+	return nil
+}
+
+// systemProfiles returns the named profiles, or every sys- profile.
+func systemProfiles(file string, names []string) ([]*examples.ConnectionProfile, error) {
+	all, err := examples.LoadConnectionProfiles(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		for _, name := range examples.ProfileNames(all) {
+			if strings.HasPrefix(name, systemProfilePrefix) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var profiles []*examples.ConnectionProfile
+	for _, name := range names {
+		p, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q", name)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// startMonitors watches each profile's server, skipping servers that are
+// not running. It returns a function that stops every monitor and how
+// many servers are watched.
+func startMonitors(profiles []*examples.ConnectionProfile, filter examples.AuthEventFilter, poll time.Duration,
+	print func(profile string, e examples.AuthEvent), verbose bool) (func(), int) {
+	var mu sync.Mutex
+	var stops []func()
+	for _, p := range profiles {
+		p := p
+		nc, err := p.Connect(nats.Name("nats-demo monitor"))
+		if err != nil {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "✗ %s (%s): %v\n", p.Name, p.URL, err)
+			}
+			continue
+		}
+		m, err := examples.WatchSystemEvents(nc, filter, poll, func(e examples.AuthEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			print(p.Name, e)
+		})
+		if err != nil {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "✗ %s (%s): %v\n", p.Name, p.URL, err)
+			}
+			nc.Close()
+			continue
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "✓ %s: watching %s\n", p.Name, p.URL)
+		}
+		stops = append(stops, func() {
+			m.Stop()
+			nc.Close()
+		})
+	}
+	return func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}, len(stops)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+)
+
+// runNKeys implements `nats-demo nkeys`.
+func runNKeys(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: nats-demo nkeys <gen|inspect|validate> [flags]")
+	}
+
+	switch args[0] {
+	case "gen":
+		return nkeysGen(args[1:])
+	case "inspect":
+		return nkeysInspect(args[1:])
+	case "validate":
+		return nkeysValidate(args[1:])
+	default:
+		return fmt.Errorf("unknown nkeys subcommand %q", args[0])
+	}
+}
+
+func nkeysGen(args []string) error {
+	fs := flag.NewFlagSet("nkeys gen", flag.ContinueOnError)
+	keyType := fs.String("type", "user", "operator, account, user, server, cluster or curve")
+	role := fs.String("role", "", "role recorded in the key inventory (defaults to the key type)")
+	inventory := fs.String("inventory", examples.DefaultInventoryFile, "key inventory to record the key in")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	prefix, err := examples.ParseNKeyType(*keyType)
+	if err != nil {
+		return err
+	}
+
+	pair, err := examples.GenerateNKey(prefix)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Type:        %v\n", pair.Type)
+	fmt.Printf("Seed:        %s\n", pair.Seed)
+	fmt.Printf("Public Key:  %s\n", pair.PublicKey)
+
+	if *role == "" {
+		*role = pair.Type.String()
+	}
+	return recordKeys(*inventory, []examples.GeneratedNKey{{Role: *role, Type: pair.Type, PublicKey: pair.PublicKey}})
+}
+
+func nkeysInspect(args []string) error {
+	fs := flag.NewFlagSet("nkeys inspect", flag.ContinueOnError)
+	users := fs.Bool("users", false, "check the NKeys demo users against --config")
+	config := fs.String("config", "config/nkeys-auth.conf", "server config the demo users connect to")
+	expect := fs.String("expect", "", "public key the inspected seed must derive")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *users {
+		return inspectDemoUsers(*config)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: nats-demo nkeys inspect <seed|public key|file> | --users [--config file]")
+	}
+
+	target := fs.Arg(0)
+	var results []examples.NKeyInspection
+	if _, err := os.Stat(target); err == nil {
+		results, err = examples.InspectNKeyFile(target)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return fmt.Errorf("no nkeys found in %s", target)
+		}
+	} else {
+		results = []examples.NKeyInspection{examples.InspectNKey(target)}
+	}
+
+	failed := 0
+	for _, in := range results {
+		examples.PrintNKeyInspection(in)
+		if in.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d keys are invalid", failed, len(results))
+	}
+
+	if *expect != "" {
+		if len(results) != 1 || !results[0].IsSeed {
+			return fmt.Errorf("--expect needs a single seed to compare against")
+		}
+		if results[0].PublicKey != *expect {
+			return fmt.Errorf("seed derives %s, not %s", results[0].PublicKey, *expect)
+		}
+		fmt.Println("✓ Seed derives the expected public key")
+	}
+	return nil
+}
+
+func inspectDemoUsers(config string) error {
+	checks, err := examples.CheckPredefinedUsers(config)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, check := range checks {
+		if len(check.Problems) == 0 {
+			fmt.Printf("✓ %s: seed matches its configured public key\n", check.Name)
+			continue
+		}
+		failed++
+		fmt.Printf("✗ %s:\n", check.Name)
+		for _, problem := range check.Problems {
+			fmt.Printf("  - %s\n", problem)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d demo users cannot authenticate against %s", failed, len(checks), config)
+	}
+	return nil
+}
+
+func nkeysValidate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: nats-demo nkeys validate <config file>")
+	}
+
+	problems, err := examples.ValidateConfigNKeys(args[0])
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Printf("✗ %v\n", problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d misplaced or invalid nkeys in %s", len(problems), args[0])
+	}
+
+	fmt.Printf("✓ Every nkey in %s has the expected key type\n", args[0])
+	return nil
+}
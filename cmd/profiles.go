@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+)
+
+// runProfiles implements `nats-demo profiles`.
+func runProfiles(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: nats-demo profiles <list|check> [flags]")
+	}
+
+	switch args[0] {
+	case "list":
+		return profilesList(args[1:])
+	case "check":
+		return profilesCheck(args[1:])
+	default:
+		return fmt.Errorf("unknown profiles subcommand %q", args[0])
+	}
+}
+
+func profilesList(args []string) error {
+	fs := flag.NewFlagSet("profiles list", flag.ContinueOnError)
+	file := fs.String("file", examples.DefaultProfilesFile, "profiles file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	profiles, err := examples.LoadConnectionProfiles(*file)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-20s %-18s %s\n", "NAME", "AUTH", "URL")
+	for _, name := range examples.ProfileNames(profiles) {
+		p := profiles[name]
+		fmt.Printf("%-20s %-18s %s\n", p.Name, p.AuthMethod(), p.URL)
+	}
+	return nil
+}
+
+// profilesCheck connects with each named profile, or all profiles.
+func profilesCheck(args []string) error {
+	fs := flag.NewFlagSet("profiles check", flag.ContinueOnError)
+	file := fs.String("file", examples.DefaultProfilesFile, "profiles file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	profiles, err := examples.LoadConnectionProfiles(*file)
+	if err != nil {
+		return err
+	}
+	names := fs.Args()
+	if len(names) == 0 {
+		names = examples.ProfileNames(profiles)
+	}
+
+	failed := 0
+	for _, name := range names {
+		p, ok := profiles[name]
+		if !ok {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+		nc, err := p.Connect()
+		if err != nil {
+			failed++
+			fmt.Printf("✗ %s: %v\n", name, err)
+			continue
+		}
+		nc.Close()
+		fmt.Printf("✓ %s: connected to %s (%s)\n", name, p.URL, p.AuthMethod())
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d profiles failed to connect", failed, len(names))
+	}
+	return nil
+}
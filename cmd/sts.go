@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats.go"
+)
+
+// runSTS implements `nats-demo sts`, the short-lived credential service.
+func runSTS(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: nats-demo sts <serve|request> [flags]")
+	}
+
+	switch args[0] {
+	case "serve":
+		return stsServe(args[1:])
+	case "request":
+		return stsRequest(args[1:])
+	default:
+		return fmt.Errorf("unknown sts subcommand %q", args[0])
+	}
+}
+
+func stsServe(args []string) error {
+	fs := flag.NewFlagSet("sts serve", flag.ContinueOnError)
+	store := fs.String("store", defaultOperatorStore, "operator store file")
+	account := fs.String("account", "", "account to issue credentials in")
+	policyFile := fs.String("policy", "config/sts-policy.json", "issuance policy")
+	url := fs.String("url", "nats://localhost:4228", "server to connect to")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *account == "" {
+		return fmt.Errorf("--account is required")
+	}
+
+	op, err := examples.LoadJWTOperator(*store)
+	if err != nil {
+		return err
+	}
+	policy, err := examples.LoadSTSPolicy(*policyFile)
+	if err != nil {
+		return err
+	}
+
+	// The service connects as a user it issues itself, allowed only to
+	// answer credential requests.
+	self, err := op.IssueUser(*account, examples.UserSpec{
+		Name:        "sts",
+		Permissions: examples.PermissionsFor([]string{"_INBOX.>"}, []string{examples.STSSubject}),
+	})
+	if err != nil {
+		return err
+	}
+	nc, err := nats.Connect(*url, self.ConnectOption(), nats.Name("sts"))
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer nc.Close()
+
+	sts := &examples.STSService{
+		Operator: op,
+		Account:  *account,
+		Policy:   policy,
+		Decisions: func(workload string, err error) {
+			if err != nil {
+				fmt.Printf("✗ refused %s: %v\n", workload, err)
+			} else {
+				fmt.Printf("✓ issued credentials to %s\n", workload)
+			}
+		},
+	}
+	if _, err := sts.Start(nc); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Serving %s in account %s for %d workloads (Ctrl-C to stop)\n",
+		examples.STSSubject, *account, len(policy.Workloads))
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	return nil
+}
+
+func stsRequest(args []string) error {
+	fs := flag.NewFlagSet("sts request", flag.ContinueOnError)
+	creds := fs.String("creds", "", "workload .creds file")
+	url := fs.String("url", "nats://localhost:4228", "server to connect to")
+	pub := fs.String("pub", "", "comma-separated subjects to publish to")
+	sub := fs.String("sub", "", "comma-separated subjects to subscribe to")
+	ttl := fs.String("ttl", "", "credential lifetime, e.g. 5m (default from policy)")
+	out := fs.String("out", "", "write the short-lived .creds file here instead of stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *creds == "" {
+		return fmt.Errorf("--creds is required")
+	}
+
+	data, err := os.ReadFile(*creds)
+	if err != nil {
+		return fmt.Errorf("failed to read creds: %w", err)
+	}
+	workloadJWT, err := jwt.ParseDecoratedJWT(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse creds: %w", err)
+	}
+	kp, err := jwt.ParseDecoratedNKey(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse creds: %w", err)
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		return fmt.Errorf("failed to read seed from creds: %w", err)
+	}
+
+	nc, err := nats.Connect(*url, nats.UserCredentials(*creds))
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer nc.Close()
+
+	resp, err := examples.RequestSTSCredentials(nc, workloadJWT, string(seed), examples.STSRequest{
+		Publish:   splitList(*pub),
+		Subscribe: splitList(*sub),
+		TTL:       *ttl,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The inbox prefix is not part of the .creds file, so it goes to stderr
+	// when the credentials go to stdout.
+	if *out == "" {
+		fmt.Print(resp.Creds)
+		fmt.Fprintf(os.Stderr, "Inbox prefix: %s (connect with nats.CustomInboxPrefix)\n", resp.InboxPrefix)
+		return nil
+	}
+	if err := os.WriteFile(*out, []byte(resp.Creds), 0600); err != nil {
+		return fmt.Errorf("failed to write creds: %w", err)
+	}
+	fmt.Printf("✓ Short-lived credentials written to %s (expire %s)\n", *out, resp.Expires.Format("15:04:05"))
+	fmt.Printf("  Inbox prefix: %s (connect with nats.CustomInboxPrefix)\n", resp.InboxPrefix)
+	return nil
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+	"github.com/nats-io/nats.go"
+)
+
+// runTenant implements `nats-demo tenant`, which edits the accounts of a
+// config file and reloads the server running it.
+func runTenant(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: nats-demo tenant <init|add|remove|grant-import> [flags]")
+	}
+
+	switch args[0] {
+	case "init":
+		return tenantInit(args[1:])
+	case "add":
+		return tenantAdd(args[1:])
+	case "remove":
+		return tenantRemove(args[1:])
+	case "grant-import":
+		return tenantGrantImport(args[1:])
+	default:
+		return fmt.Errorf("unknown tenant subcommand %q", args[0])
+	}
+}
+
+// defaultTenantConfig is the file tenant subcommands edit unless --config
+// is given. It starts as a copy of defaultTenantSource, so the tracked
+// demo config and its comments are never rewritten.
+const (
+	defaultTenantConfig = "generated/tenants.conf"
+	defaultTenantSource = "config/accounts.conf"
+)
+
+// tenantStore is a store opened from the shared flags.
+type tenantStore struct {
+	*examples.TenantStore
+	// confirmed is set when reloads are confirmed by the server.
+	confirmed bool
+	close     func()
+}
+
+// tenantStoreFlags adds the flags every tenant subcommand shares and
+// returns a function opening the store they describe.
+func tenantStoreFlags(fs *flag.FlagSet) func() (*tenantStore, error) {
+	config := fs.String("config", defaultTenantConfig, "multi-account config file to edit")
+	pid := fs.String("pid", "", "nats-server pid to signal to reload the config")
+	pidFile := fs.String("pid-file", "", "nats-server pid_file to read the pid from")
+	sysUser := fs.String("sys-user", "", "system account USER:PASSWORD to reload through and confirm the reload")
+	url := fs.String("url", "nats://localhost:4226", "server to connect to with --sys-user")
+	return func() (*tenantStore, error) {
+		if *config == defaultTenantConfig {
+			if _, err := seedTenantConfig(*config, defaultTenantSource); err != nil {
+				return nil, err
+			}
+		}
+		store, err := examples.OpenTenantStore(*config)
+		if err != nil {
+			return nil, err
+		}
+		if *sysUser != "" {
+			user, password, ok := strings.Cut(*sysUser, ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid --sys-user %q (want USER:PASSWORD)", *sysUser)
+			}
+			nc, err := nats.Connect(*url, nats.UserInfo(user, password))
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect as %s: %w", user, err)
+			}
+			store.Reload = examples.SystemReloader(nc)
+			return &tenantStore{TenantStore: store, confirmed: true, close: nc.Close}, nil
+		}
+		if *pidFile != "" {
+			if *pid, err = examples.ReadPIDFile(*pidFile); err != nil {
+				return nil, err
+			}
+		}
+		if *pid != "" {
+			store.Reload = examples.SignalReloader(*pid)
+		}
+		return &tenantStore{TenantStore: store, close: func() {}}, nil
+	}
+}
+
+// seedTenantConfig copies from to file unless file exists, and reports
+// whether it did.
+func seedTenantConfig(file, from string) (bool, error) {
+	if _, err := os.Stat(file); err == nil {
+		return false, nil
+	}
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", from, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	fmt.Printf("✓ Copied %s to %s\n", from, file)
+	return true, nil
+}
+
+func tenantInit(args []string) error {
+	fs := flag.NewFlagSet("tenant init", flag.ContinueOnError)
+	config := fs.String("config", defaultTenantConfig, "multi-account config file to create")
+	from := fs.String("from", defaultTenantSource, "config to copy the accounts from")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: nats-demo tenant init [--config FILE] [--from FILE]")
+	}
+
+	if _, err := examples.OpenTenantStore(*from); err != nil {
+		return err
+	}
+	copied, err := seedTenantConfig(*config, *from)
+	if err != nil {
+		return err
+	}
+	if !copied {
+		fmt.Printf("✓ %s already exists; left unchanged\n", *config)
+	}
+	return nil
+}
+
+// reportTenantChange prints the outcome of a change.
+func reportTenantChange(store *tenantStore, what string, err error) error {
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ %s; wrote %s\n", what, store.File)
+	switch {
+	case store.confirmed:
+		fmt.Println("✓ nats-server reloaded the config")
+	case store.Reload != nil:
+		fmt.Println("✓ Signalled nats-server to reload (check its log: a rejected reload keeps the new file)")
+	default:
+		fmt.Println("  No --pid or --sys-user given; reload with: nats-server --signal reload=<pid>")
+	}
+	return nil
+}
+
+func tenantAdd(args []string) error {
+	fs := flag.NewFlagSet("tenant add", flag.ContinueOnError)
+	open := tenantStoreFlags(fs)
+	users := fs.String("users", "", "comma-separated USER:PASSWORD pairs")
+	streams := fs.String("streams", "", "comma-separated stream subjects to export")
+	services := fs.String("services", "", "comma-separated service subjects to export")
+	privateTo := fs.String("private-to", "", "comma-separated accounts the exports are private to (default public)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: nats-demo tenant add NAME --users USER:PASSWORD,... [--streams S,...] [--services S,...] [--private-to A,...]")
+	}
+
+	acc := examples.ConfigAccount{Name: fs.Arg(0)}
+	for _, pair := range splitList(*users) {
+		user, password, ok := strings.Cut(pair, ":")
+		if !ok || user == "" || password == "" {
+			return fmt.Errorf("invalid --users entry %q (want USER:PASSWORD)", pair)
+		}
+		acc.Users = append(acc.Users, examples.ConfigUser{User: user, Password: password})
+	}
+	if len(acc.Users) == 0 {
+		return fmt.Errorf("tenant %s needs at least one user (--users)", acc.Name)
+	}
+	for _, subject := range splitList(*streams) {
+		acc.Exports = append(acc.Exports, examples.ConfigExport{Stream: subject, Accounts: splitList(*privateTo)})
+	}
+	for _, subject := range splitList(*services) {
+		acc.Exports = append(acc.Exports, examples.ConfigExport{Service: subject, Accounts: splitList(*privateTo)})
+	}
+
+	store, err := open()
+	if err != nil {
+		return err
+	}
+	defer store.close()
+	return reportTenantChange(store, fmt.Sprintf("Added account %s with %d user(s) and %d export(s)",
+		acc.Name, len(acc.Users), len(acc.Exports)), store.AddTenant(acc))
+}
+
+func tenantRemove(args []string) error {
+	fs := flag.NewFlagSet("tenant remove", flag.ContinueOnError)
+	open := tenantStoreFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: nats-demo tenant remove NAME")
+	}
+
+	store, err := open()
+	if err != nil {
+		return err
+	}
+	defer store.close()
+	return reportTenantChange(store, "Removed account "+fs.Arg(0), store.RemoveTenant(fs.Arg(0)))
+}
+
+func tenantGrantImport(args []string) error {
+	fs := flag.NewFlagSet("tenant grant-import", flag.ContinueOnError)
+	open := tenantStoreFlags(fs)
+	from := fs.String("from", "", "exporting account")
+	stream := fs.String("stream", "", "stream subject to import")
+	service := fs.String("service", "", "service subject to import")
+	prefix := fs.String("prefix", "", "prefix for an imported stream")
+	to := fs.String("to", "", "local subject for an imported service")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *from == "" || (*stream == "") == (*service == "") {
+		return fmt.Errorf("usage: nats-demo tenant grant-import IMPORTER --from ACCOUNT (--stream S [--prefix P] | --service S [--to T])")
+	}
+
+	imp := examples.ConfigImport{Account: *from, Stream: *stream, Service: *service, Prefix: *prefix, To: *to}
+	kind, subject := "stream", *stream
+	if *service != "" {
+		kind, subject = "service", *service
+	}
+
+	store, err := open()
+	if err != nil {
+		return err
+	}
+	defer store.close()
+	return reportTenantChange(store, fmt.Sprintf("%s imports %s %s from %s", fs.Arg(0), kind, subject, *from),
+		store.GrantImport(fs.Arg(0), imp))
+}
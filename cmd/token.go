@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+)
+
+// runToken implements `nats-demo token`.
+func runToken(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: nats-demo token <hash|config> [flags]")
+	}
+
+	switch args[0] {
+	case "hash":
+		return tokenHash(args[1:])
+	case "config":
+		return tokenConfig(args[1:])
+	default:
+		return fmt.Errorf("unknown token subcommand %q", args[0])
+	}
+}
+
+// tokenHash reads a token or password from stdin and prints its bcrypt hash.
+func tokenHash(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: nats-demo token hash < secret")
+	}
+
+	secret, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && secret == "" {
+		return fmt.Errorf("failed to read secret from stdin: %w", err)
+	}
+
+	hash, err := examples.HashSecret(strings.TrimSpace(secret))
+	if err != nil {
+		return err
+	}
+	fmt.Println(hash)
+	return nil
+}
+
+// tokenConfig writes a token-auth server config for a newly generated
+// token and prints the token once.
+func tokenConfig(args []string) error {
+	fs := flag.NewFlagSet("token config", flag.ContinueOnError)
+	out := fs.String("config", "generated/token-auth.conf", "server config to generate")
+	port := fs.Int("port", 4230, "server port for the generated config")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	cfg := &examples.ServerConfig{
+		Header:      []string{"Generated Token Authentication Configuration", "The token is stored as a bcrypt hash"},
+		Port:        *port,
+		Token:       token,
+		HashSecrets: true,
+	}
+	if err := cfg.WriteFile(*out); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Server config written to %s\n", *out)
+	fmt.Printf("  Token (shown once): %s\n", token)
+	return nil
+}
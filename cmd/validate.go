@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/anubhavg-icpl/nats-auth-demo/examples"
+)
+
+// runValidate implements `nats-demo validate`, checking the imports and
+// exports of a multi-account config.
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	sources := fs.String("sources", "", "comma-separated ACCOUNT:SUBJECT pairs to list the stream sources of")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: nats-demo validate <config> [--sources ACCOUNT:SUBJECT,...]")
+	}
+
+	g, err := examples.LoadAccountGraph(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range splitList(*sources) {
+		account, subject, ok := strings.Cut(pair, ":")
+		if !ok || account == "" || subject == "" {
+			return fmt.Errorf("invalid --sources entry %q (want ACCOUNT:SUBJECT)", pair)
+		}
+		if from := g.StreamSources(account, subject); len(from) > 0 {
+			fmt.Printf("✓ %s can receive %s from accounts: %s\n", account, subject, strings.Join(from, ", "))
+		} else {
+			fmt.Printf("✗ %s cannot receive %s from any other account\n", account, subject)
+		}
+	}
+
+	issues := examples.ValidateAccounts(g)
+	if len(issues) == 0 {
+		fmt.Printf("✓ %s: %d accounts, %d imports, no issues\n", fs.Arg(0), len(g.Accounts), len(g.Edges))
+		return nil
+	}
+	for _, issue := range issues {
+		fmt.Printf("✗ %s\n", issue)
+	}
+	return fmt.Errorf("%s: %d issues", fs.Arg(0), len(issues))
+}
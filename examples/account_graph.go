@@ -0,0 +1,293 @@
+package examples
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nats-io/nats-server/v2/conf"
+)
+
+// AccountGraph is the accounts of a server config with their users, and
+// the imports between them as edges from exporter to importer.
+type AccountGraph struct {
+	Accounts []*GraphAccount `json:"accounts"`
+	Edges    []GraphEdge     `json:"edges"`
+	// NoAuthUser is the user unauthenticated clients are bound to, if any.
+	NoAuthUser string `json:"no_auth_user,omitempty"`
+}
+
+// GraphAccount is one account node.
+type GraphAccount struct {
+	Name    string        `json:"name"`
+	Users   []string      `json:"users"`
+	Exports []GraphExport `json:"exports,omitempty"`
+	// NoAuth is set on the account that holds the no_auth_user.
+	NoAuth bool `json:"no_auth,omitempty"`
+}
+
+// GraphExport is a stream or service an account exports. Accounts lists
+// who may import a private export; it is empty for a public one.
+type GraphExport struct {
+	Kind     string   `json:"kind"`
+	Subject  string   `json:"subject"`
+	Accounts []string `json:"accounts,omitempty"`
+}
+
+// Public reports whether any account may import the export.
+func (e GraphExport) Public() bool {
+	return len(e.Accounts) == 0
+}
+
+// GraphEdge is an import. Subject is the subject imported from From;
+// Prefix (streams) or To (services) is where it appears in the importer.
+type GraphEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+	Prefix  string `json:"prefix,omitempty"`
+	Remap   string `json:"remap,omitempty"`
+	// Export is the matching export in From, or nil when there is none.
+	Export *GraphExport `json:"export,omitempty"`
+}
+
+// Visibility describes the export behind the edge.
+func (e GraphEdge) Visibility() string {
+	switch {
+	case e.Export == nil:
+		return "no matching export"
+	case e.Export.Public():
+		return "public"
+	}
+	return "private"
+}
+
+// LocalSubject is the subject the importer uses.
+func (e GraphEdge) LocalSubject() string {
+	switch {
+	case e.Prefix != "":
+		return e.Prefix + "." + e.Subject
+	case e.Remap != "":
+		return e.Remap
+	}
+	return e.Subject
+}
+
+// LoadAccountGraph reads the accounts block of a server config.
+func LoadAccountGraph(filename string) (*AccountGraph, error) {
+	cfg, err := conf.ParseFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return accountGraph(cfg, filename)
+}
+
+// accountGraph builds the graph from a parsed config; source names the
+// config in errors.
+func accountGraph(cfg map[string]interface{}, source string) (*AccountGraph, error) {
+	accounts, ok := cfg["accounts"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s has no accounts block", source)
+	}
+
+	g := &AccountGraph{}
+	g.NoAuthUser, _ = cfg["no_auth_user"].(string)
+
+	byName := map[string]*GraphAccount{}
+	imports := map[string][]interface{}{}
+	for name, v := range accounts {
+		acc := &GraphAccount{Name: name}
+		body, _ := v.(map[string]interface{})
+		for _, u := range configList(body["users"]) {
+			user := configUserName(u)
+			acc.Users = append(acc.Users, user)
+			if user != "" && user == g.NoAuthUser {
+				acc.NoAuth = true
+			}
+		}
+		for _, e := range configList(body["exports"]) {
+			if export, ok := parseGraphExport(e); ok {
+				acc.Exports = append(acc.Exports, export)
+			}
+		}
+		imports[name] = configList(body["imports"])
+		byName[name] = acc
+		g.Accounts = append(g.Accounts, acc)
+	}
+	sort.Slice(g.Accounts, func(i, j int) bool { return g.Accounts[i].Name < g.Accounts[j].Name })
+
+	for _, acc := range g.Accounts {
+		for _, imp := range imports[acc.Name] {
+			edge, ok := parseGraphImport(acc.Name, imp)
+			if !ok {
+				continue
+			}
+			if exporter := byName[edge.From]; exporter != nil {
+				edge.Export = exporter.findExport(edge.Kind, edge.Subject)
+			}
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+	return g, nil
+}
+
+// findExport returns the export of kind that covers subject.
+func (a *GraphAccount) findExport(kind, subject string) *GraphExport {
+	for i, e := range a.Exports {
+		if e.Kind == kind && (e.Subject == subject || subjectCovers(e.Subject, subject)) {
+			return &a.Exports[i]
+		}
+	}
+	return nil
+}
+
+func configList(v interface{}) []interface{} {
+	list, _ := v.([]interface{})
+	return list
+}
+
+// configUserName returns a user's name, or a shortened nkey.
+func configUserName(v interface{}) string {
+	u, _ := v.(map[string]interface{})
+	if name, ok := u["user"].(string); ok {
+		return name
+	}
+	if nkey, ok := u["nkey"].(string); ok && len(nkey) > 10 {
+		return nkey[:10] + "..."
+	}
+	return ""
+}
+
+func parseGraphExport(v interface{}) (GraphExport, bool) {
+	m, _ := v.(map[string]interface{})
+	for _, kind := range []string{"stream", "service"} {
+		if subject, ok := m[kind].(string); ok {
+			return GraphExport{Kind: kind, Subject: subject, Accounts: configSubjects(m["accounts"])}, true
+		}
+	}
+	return GraphExport{}, false
+}
+
+func parseGraphImport(importer string, v interface{}) (GraphEdge, bool) {
+	m, _ := v.(map[string]interface{})
+	for _, kind := range []string{"stream", "service"} {
+		src, ok := m[kind].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		edge := GraphEdge{To: importer, Kind: kind}
+		edge.From, _ = src["account"].(string)
+		edge.Subject, _ = src["subject"].(string)
+		edge.Prefix, _ = m["prefix"].(string)
+		edge.Remap, _ = m["to"].(string)
+		return edge, true
+	}
+	return GraphEdge{}, false
+}
+
+// edgeLabel is the text shown on an edge.
+func (e GraphEdge) edgeLabel() string {
+	label := fmt.Sprintf("%s %s (%s)", e.Kind, e.Subject, e.Visibility())
+	switch {
+	case e.Prefix != "":
+		label += fmt.Sprintf(" prefix %s -> %s", e.Prefix, e.LocalSubject())
+	case e.Remap != "":
+		label += " to " + e.Remap
+	}
+	return label
+}
+
+// nodeLines are the lines of text shown in an account node.
+func (a *GraphAccount) nodeLines(noAuthUser string) []string {
+	lines := []string{"Account " + a.Name}
+	for _, u := range a.Users {
+		if u == noAuthUser {
+			u += " (no_auth_user)"
+		}
+		lines = append(lines, "user: "+u)
+	}
+	for _, e := range a.Exports {
+		who := "public"
+		if !e.Public() {
+			who = "to " + strings.Join(e.Accounts, ", ")
+		}
+		lines = append(lines, fmt.Sprintf("exports %s %s (%s)", e.Kind, e.Subject, who))
+	}
+	return lines
+}
+
+// DOT renders the graph for Graphviz. Streams are solid edges, services
+// dashed; private exports are red and the no_auth_user's account is bold.
+func (g *AccountGraph) DOT() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "digraph accounts {")
+	fmt.Fprintln(&b, "  rankdir=LR;")
+	fmt.Fprintln(&b, `  node [shape=box, style=rounded, fontname="Helvetica"];`)
+	fmt.Fprintln(&b, `  edge [fontname="Helvetica", fontsize=10];`)
+	for _, a := range g.Accounts {
+		// %q writes newlines as \n, which DOT also reads as a line break.
+		attrs := fmt.Sprintf("label=%q", strings.Join(a.nodeLines(g.NoAuthUser), "\n"))
+		if a.NoAuth {
+			attrs += `, style="rounded,bold", color=darkorange, penwidth=2`
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", a.Name, attrs)
+	}
+	for _, e := range g.Edges {
+		attrs := fmt.Sprintf("label=%q", e.edgeLabel())
+		if e.Kind == "service" {
+			attrs += ", style=dashed"
+		}
+		switch e.Visibility() {
+		case "private":
+			attrs += ", color=firebrick, fontcolor=firebrick"
+		case "no matching export":
+			attrs += ", color=gray, fontcolor=gray"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [%s];\n", e.From, e.To, attrs)
+	}
+	fmt.Fprintln(&b, "}")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart. Streams are solid
+// edges, services dotted; the no_auth_user's account is highlighted.
+func (g *AccountGraph) Mermaid() string {
+	quote := func(s string) string {
+		return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "flowchart LR")
+	for _, a := range g.Accounts {
+		fmt.Fprintf(&b, "  %s[%s]\n", a.Name, quote(strings.Join(a.nodeLines(g.NoAuthUser), "<br/>")))
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == "service" {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", e.From, arrow, quote(e.edgeLabel()), e.To)
+	}
+	for _, a := range g.Accounts {
+		if a.NoAuth {
+			fmt.Fprintln(&b, "  classDef noauth stroke:#d97706,stroke-width:3px")
+			fmt.Fprintf(&b, "  class %s noauth\n", a.Name)
+		}
+	}
+	return b.String()
+}
+
+// JSON renders the graph as indented JSON.
+func (g *AccountGraph) JSON() ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(g); err != nil {
+		return nil, fmt.Errorf("failed to encode graph: %w", err)
+	}
+	return b.Bytes(), nil
+}
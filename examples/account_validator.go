@@ -0,0 +1,243 @@
+package examples
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AccountIssue is a problem ValidateAccounts found in a multi-account
+// config.
+type AccountIssue struct {
+	// Account is the importing account the issue is reported against.
+	Account string
+	// Check is the rule that failed, e.g. "missing-export".
+	Check   string
+	Message string
+}
+
+func (i AccountIssue) String() string {
+	return fmt.Sprintf("%s: [%s] %s", i.Account, i.Check, i.Message)
+}
+
+// String describes the import, e.g. `B's stream import of A "b.>"`.
+func (e GraphEdge) String() string {
+	return fmt.Sprintf("%s's %s import of %s %q", e.To, e.Kind, e.From, e.Subject)
+}
+
+// Authorized reports whether a matching export allows the importer.
+func (e GraphEdge) Authorized() bool {
+	if e.Export == nil {
+		return false
+	}
+	if e.Export.Public() {
+		return true
+	}
+	for _, acc := range e.Export.Accounts {
+		if acc == e.To {
+			return true
+		}
+	}
+	return false
+}
+
+// subjectsOverlap reports whether some subject matches both a and b.
+func subjectsOverlap(a, b string) bool {
+	at := strings.Split(a, ".")
+	bt := strings.Split(b, ".")
+	for i := 0; ; i++ {
+		if i >= len(at) || i >= len(bt) {
+			return len(at) == len(bt)
+		}
+		if at[i] == ">" || bt[i] == ">" {
+			return true
+		}
+		if at[i] != "*" && bt[i] != "*" && at[i] != bt[i] {
+			return false
+		}
+	}
+}
+
+// ValidateAccounts checks the imports and exports of a multi-account
+// config. It reports imports of missing accounts or exports, imports of
+// private exports the importer is not listed on, stream prefixes that
+// collide with the importer's own exports or imports, service `to`
+// remaps that shadow other imports, and cycles of service imports.
+func ValidateAccounts(g *AccountGraph) []AccountIssue {
+	var issues []AccountIssue
+	report := func(account, check, format string, args ...interface{}) {
+		issues = append(issues, AccountIssue{Account: account, Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	byName := map[string]*GraphAccount{}
+	for _, a := range g.Accounts {
+		byName[a.Name] = a
+	}
+
+	for _, e := range g.Edges {
+		exporter := byName[e.From]
+		switch {
+		case e.From == "":
+			report(e.To, "missing-account", "%s names no account", e)
+		case exporter == nil:
+			report(e.To, "missing-account", "%s: account %s does not exist", e, e.From)
+		case e.Export == nil:
+			msg := fmt.Sprintf("%s: %s has no %s export covering %q", e, e.From, e.Kind, e.Subject)
+			other := "service"
+			if e.Kind == "service" {
+				other = "stream"
+			}
+			if exporter.findExport(other, e.Subject) != nil {
+				msg += fmt.Sprintf(" (it is exported as a %s)", other)
+			}
+			report(e.To, "missing-export", "%s", msg)
+		case !e.Authorized():
+			report(e.To, "not-authorized", "%s: %s exports %q only to %s",
+				e, e.From, e.Export.Subject, strings.Join(e.Export.Accounts, ", "))
+		}
+
+		importer := byName[e.To]
+		if e.Kind == "stream" && e.Prefix != "" && importer != nil {
+			for _, x := range importer.Exports {
+				if subjectsOverlap(e.LocalSubject(), x.Subject) {
+					report(e.To, "prefix-collision", "%s with prefix %s lands on %q, which overlaps %s's own %s export %q",
+						e, e.Prefix, e.LocalSubject(), e.To, x.Kind, x.Subject)
+				}
+			}
+		}
+	}
+
+	// Imports into the same account that overlap: a prefixed stream import
+	// collides with the other one, and a remapped service shadows it.
+	for i, e := range g.Edges {
+		for _, other := range g.Edges[i+1:] {
+			if other.To != e.To || !subjectsOverlap(e.LocalSubject(), other.LocalSubject()) {
+				continue
+			}
+			switch {
+			case e.Kind == "stream" && e.Prefix != "":
+				report(e.To, "prefix-collision", "%s with prefix %s lands on %q, which overlaps %s on %q",
+					e, e.Prefix, e.LocalSubject(), other, other.LocalSubject())
+			case other.Kind == "stream" && other.Prefix != "":
+				report(e.To, "prefix-collision", "%s with prefix %s lands on %q, which overlaps %s on %q",
+					other, other.Prefix, other.LocalSubject(), e, e.LocalSubject())
+			case e.Kind == "service" && e.Remap != "":
+				report(e.To, "remap-shadow", "%s remapped to %q shadows %s on %q",
+					e, e.Remap, other, other.LocalSubject())
+			case other.Kind == "service" && other.Remap != "":
+				report(e.To, "remap-shadow", "%s remapped to %q shadows %s on %q",
+					other, other.Remap, e, e.LocalSubject())
+			}
+		}
+	}
+
+	for _, cycle := range serviceCycles(g.Edges) {
+		var hops []string
+		for _, e := range cycle {
+			hops = append(hops, fmt.Sprintf("%s -[%s]-> %s", e.To, e.LocalSubject(), e.From))
+		}
+		report(cycle[0].To, "service-cycle", "requests loop through service imports: %s", strings.Join(hops, ", "))
+	}
+	return issues
+}
+
+// serviceCycles finds loops of service imports: a request in the importer
+// goes to the exporter, which forwards it again if it imports a service on
+// an overlapping subject.
+func serviceCycles(edges []GraphEdge) [][]GraphEdge {
+	var services []int
+	for i, e := range edges {
+		if e.Kind == "service" {
+			services = append(services, i)
+		}
+	}
+	next := func(i int) []int {
+		var out []int
+		for _, j := range services {
+			if edges[j].To == edges[i].From && subjectsOverlap(edges[j].LocalSubject(), edges[i].Subject) {
+				out = append(out, j)
+			}
+		}
+		return out
+	}
+
+	var cycles [][]GraphEdge
+	seen := map[string]bool{}
+	var path []int
+	onPath := map[int]bool{}
+	var walk func(i int)
+	walk = func(i int) {
+		path = append(path, i)
+		onPath[i] = true
+		for _, j := range next(i) {
+			if !onPath[j] {
+				walk(j)
+				continue
+			}
+			var start int
+			for start = range path {
+				if path[start] == j {
+					break
+				}
+			}
+			loop := append([]int(nil), path[start:]...)
+			key := append([]int(nil), loop...)
+			sort.Ints(key)
+			if id := fmt.Sprint(key); !seen[id] {
+				seen[id] = true
+				var cycle []GraphEdge
+				for _, k := range loop {
+					cycle = append(cycle, edges[k])
+				}
+				cycles = append(cycles, cycle)
+			}
+		}
+		onPath[i] = false
+		path = path[:len(path)-1]
+	}
+	for _, i := range services {
+		walk(i)
+	}
+	return cycles
+}
+
+// StreamSources lists the other accounts whose messages can arrive on
+// subject in account, following authorized stream imports transitively.
+// An empty result proves from the config alone that only the account's
+// own clients publish there.
+func (g *AccountGraph) StreamSources(account, subject string) []string {
+	found := map[string]bool{}
+	visited := map[string]bool{}
+	var walk func(account, subject string)
+	walk = func(account, subject string) {
+		key := account + " " + subject
+		if visited[key] {
+			return
+		}
+		visited[key] = true
+		for _, e := range g.Edges {
+			if e.To != account || e.Kind != "stream" || !e.Authorized() ||
+				!subjectsOverlap(e.LocalSubject(), subject) {
+				continue
+			}
+			found[e.From] = true
+			// The subject as published in the exporter.
+			upstream := e.Subject
+			if e.Prefix != "" && strings.HasPrefix(subject, e.Prefix+".") {
+				upstream = strings.TrimPrefix(subject, e.Prefix+".")
+			} else if e.Prefix == "" {
+				upstream = subject
+			}
+			walk(e.From, upstream)
+		}
+	}
+	walk(account, subject)
+
+	delete(found, account)
+	var sources []string
+	for name := range found {
+		sources = append(sources, name)
+	}
+	sort.Strings(sources)
+	return sources
+}
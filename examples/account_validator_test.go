@@ -0,0 +1,114 @@
+package examples
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestSubjectsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"a.b", "a.b", true},
+		{"a.b", "a.c", false},
+		{"a.*", "a.b", true},
+		{"a.>", "a.b.c", true},
+		{"a.>", "a", false},
+		{"a.*", "a", false},
+		{"a.*", "a.b.c", false},
+		{"*", ">", true},
+		{"*", "a.b", false},
+		{">", "a.b.c", true},
+		{"*.b", "a.*", true},
+		{"from_a.>", "from_a.puba.>", true},
+	}
+	for _, tt := range tests {
+		if got := subjectsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("subjectsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := subjectsOverlap(tt.b, tt.a); got != tt.want {
+			t.Errorf("subjectsOverlap(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func loadTestGraph(t *testing.T, filename string) *AccountGraph {
+	t.Helper()
+	g, err := LoadAccountGraph(filename)
+	if err != nil {
+		t.Fatalf("LoadAccountGraph(%s) failed: %v", filename, err)
+	}
+	return g
+}
+
+func TestValidateAccounts(t *testing.T) {
+	tests := []struct {
+		config string
+		want   []string
+	}{
+		{config: "../config/accounts.conf"},
+		{config: "../config/accounts-invalid.conf", want: []string{
+			"A service-cycle",
+			"B missing-account",
+			"B missing-export",
+			"B prefix-collision",
+			"C not-authorized",
+			"C remap-shadow",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			var got []string
+			for _, issue := range ValidateAccounts(loadTestGraph(t, tt.config)) {
+				got = append(got, issue.Account+" "+issue.Check)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ValidateAccounts = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ValidateAccounts = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestServiceCycles(t *testing.T) {
+	cycles := serviceCycles(loadTestGraph(t, "../config/accounts-invalid.conf").Edges)
+	if len(cycles) != 1 || len(cycles[0]) != 2 {
+		t.Fatalf("serviceCycles = %v, want one cycle of two imports", cycles)
+	}
+	if cycles := serviceCycles(loadTestGraph(t, "../config/accounts.conf").Edges); len(cycles) != 0 {
+		t.Fatalf("serviceCycles of accounts.conf = %v, want none", cycles)
+	}
+}
+
+func TestStreamSources(t *testing.T) {
+	g := loadTestGraph(t, "../config/accounts.conf")
+
+	tests := []struct {
+		account, subject string
+		want             []string
+	}{
+		// b.> is exported to B only, so C's subscribers see C's own b.data.
+		{"C", "b.data", nil},
+		{"B", "b.data", []string{"A"}},
+		{"A", "puba.x", nil},
+		{"C", "from_a.puba.x", []string{"A"}},
+	}
+	for _, tt := range tests {
+		got := g.StreamSources(tt.account, tt.subject)
+		if len(got) != len(tt.want) {
+			t.Errorf("StreamSources(%s, %s) = %v, want %v", tt.account, tt.subject, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("StreamSources(%s, %s) = %v, want %v", tt.account, tt.subject, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,552 @@
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats-server/v2/conf"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AuthCalloutSubject is where the server sends authorization requests for
+// clients that are not one of the callout's auth_users.
+const AuthCalloutSubject = "$SYS.REQ.USER.AUTH"
+
+// DefaultCalloutAccount is the account callout users are placed in when the
+// user store does not name one.
+const DefaultCalloutAccount = "APP"
+
+// CalloutUser is one entry in the callout's user store. Passwords and
+// tokens are stored as bcrypt hashes.
+type CalloutUser struct {
+	Name         string `json:"name"`
+	PasswordHash string `json:"password_hash,omitempty"`
+	TokenHash    string `json:"token_hash,omitempty"`
+	Role         string `json:"role"`
+	Account      string `json:"account,omitempty"`
+}
+
+// CalloutUserStore is a JSON file of users the auth callout accepts.
+type CalloutUserStore struct {
+	Users []*CalloutUser `json:"users"`
+	path  string
+}
+
+// LoadCalloutUserStore reads the store at path. A missing file yields an
+// empty store that Save will create.
+func LoadCalloutUserStore(path string) (*CalloutUserStore, error) {
+	store := &CalloutUserStore{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse user store: %w", err)
+	}
+	return store, nil
+}
+
+// Save writes the store back to the file it was loaded from.
+func (s *CalloutUserStore) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode user store: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write user store: %w", err)
+	}
+	return nil
+}
+
+// Find returns the user with the given name, or nil.
+func (s *CalloutUserStore) Find(name string) *CalloutUser {
+	for _, u := range s.Users {
+		if u.Name == name {
+			return u
+		}
+	}
+	return nil
+}
+
+// SetUser adds or replaces a user. Either password or token may be empty,
+// but not both.
+func (s *CalloutUserStore) SetUser(name, role, account, password, token string) error {
+	if password == "" && token == "" {
+		return fmt.Errorf("user %s needs a password or a token", name)
+	}
+
+	u := &CalloutUser{Name: name, Role: strings.ToUpper(role), Account: account}
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash password for %s: %w", name, err)
+		}
+		u.PasswordHash = string(hash)
+	}
+	if token != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(token), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash token for %s: %w", name, err)
+		}
+		u.TokenHash = string(hash)
+	}
+
+	for i, existing := range s.Users {
+		if existing.Name == name {
+			s.Users[i] = u
+			return nil
+		}
+	}
+	s.Users = append(s.Users, u)
+	return nil
+}
+
+// Authenticate checks the user/password or token a client sent in its
+// CONNECT and returns the matching user.
+func (s *CalloutUserStore) Authenticate(opts jwt.ConnectOptions) (*CalloutUser, error) {
+	if opts.Username != "" {
+		u := s.Find(opts.Username)
+		if u == nil || u.PasswordHash == "" {
+			return nil, fmt.Errorf("unknown user %q", opts.Username)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(opts.Password)) != nil {
+			return nil, fmt.Errorf("bad password for %q", opts.Username)
+		}
+		return u, nil
+	}
+
+	if opts.Token != "" {
+		for _, u := range s.Users {
+			if u.TokenHash != "" && bcrypt.CompareHashAndPassword([]byte(u.TokenHash), []byte(opts.Token)) == nil {
+				return u, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown token")
+	}
+
+	return nil, fmt.Errorf("no user/password or token presented")
+}
+
+// LoadRoleTemplates reads the permission templates (ADMIN, REQUESTOR,
+// RESPONDER, ...) defined in a config's authorization block, so callout
+// users get the same permissions as the static users in that config.
+func LoadRoleTemplates(filename string) (map[string]jwt.Permissions, error) {
+	cfg, err := conf.ParseFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	auth, _ := cfg["authorization"].(map[string]interface{})
+	roles := map[string]jwt.Permissions{}
+	for name, v := range auth {
+		tmpl, ok := v.(map[string]interface{})
+		if !ok || name != strings.ToUpper(name) {
+			continue
+		}
+		var perms jwt.Permissions
+		perms.Pub = permissionFromConfig(tmpl["publish"])
+		perms.Sub = permissionFromConfig(tmpl["subscribe"])
+		roles[name] = perms
+	}
+
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("no role templates found in %s", filename)
+	}
+	return roles, nil
+}
+
+// permissionFromConfig converts a publish or subscribe value, which may be
+// a subject, a list of subjects or an allow/deny map.
+func permissionFromConfig(v interface{}) jwt.Permission {
+	var p jwt.Permission
+	switch v := v.(type) {
+	case string, []interface{}:
+		p.Allow.Add(configSubjects(v)...)
+	case map[string]interface{}:
+		p.Allow.Add(configSubjects(v["allow"])...)
+		p.Deny.Add(configSubjects(v["deny"])...)
+	}
+	return p
+}
+
+func configSubjects(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var subjects []string
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				subjects = append(subjects, s)
+			}
+		}
+		return subjects
+	}
+	return nil
+}
+
+// AuthCallout answers the server's authorization requests by checking the
+// user store and returning a user JWT, signed by the issuer key, with the
+// permissions of the user's role.
+type AuthCallout struct {
+	Issuer    nkeys.KeyPair
+	Store     *CalloutUserStore
+	Roles     map[string]jwt.Permissions
+	ExpiresIn time.Duration
+
+	// Decisions, if set, is called with every accept or reject.
+	Decisions func(user string, err error)
+}
+
+// Start subscribes the callout service on an auth user connection.
+func (a *AuthCallout) Start(nc *nats.Conn) (*nats.Subscription, error) {
+	return startCallout(nc, a.Issuer, a.ExpiresIn, a.Decisions, a.authorize)
+}
+
+// authorize maps an accepted request to user claims for the user's role.
+func (a *AuthCallout) authorize(req *jwt.AuthorizationRequestClaims) (*jwt.UserClaims, error) {
+	u, err := a.Store.Authenticate(req.ConnectOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	perms, ok := a.Roles[u.Role]
+	if !ok {
+		return nil, fmt.Errorf("user %q has unknown role %q", u.Name, u.Role)
+	}
+
+	account := u.Account
+	if account == "" {
+		account = DefaultCalloutAccount
+	}
+
+	uc := jwt.NewUserClaims(req.UserNkey)
+	uc.Name = u.Name
+	uc.Audience = account
+	uc.Permissions = perms
+	return uc, nil
+}
+
+// calloutAuthorizer decides an authorization request. The returned claims
+// name the user, and their audience selects the account.
+type calloutAuthorizer func(req *jwt.AuthorizationRequestClaims) (*jwt.UserClaims, error)
+
+// startCallout serves $SYS.REQ.USER.AUTH, signing the user JWT and the
+// response with issuer.
+func startCallout(nc *nats.Conn, issuer nkeys.KeyPair, expiresIn time.Duration,
+	decisions func(user string, err error), authorize calloutAuthorizer) (*nats.Subscription, error) {
+	handle := func(msg *nats.Msg) {
+		req, err := jwt.DecodeAuthorizationRequestClaims(string(msg.Data))
+		if err != nil {
+			log.Printf("Auth callout: bad request: %v", err)
+			return
+		}
+
+		name := req.ConnectOptions.Username
+		if name == "" {
+			name = "<token>"
+		}
+
+		resp := jwt.NewAuthorizationResponseClaims(req.UserNkey)
+		resp.Audience = req.Server.ID
+
+		uc, err := authorize(req)
+		if err == nil {
+			name = uc.Name
+			if expiresIn > 0 && uc.Expires == 0 {
+				uc.Expires = time.Now().Add(expiresIn).Unix()
+			}
+			resp.Jwt, err = uc.Encode(issuer)
+		}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		if decisions != nil {
+			if err == nil {
+				name = fmt.Sprintf("%s (account %s)", name, uc.Audience)
+			}
+			decisions(name, err)
+		}
+
+		token, err := resp.Encode(issuer)
+		if err != nil {
+			log.Printf("Auth callout: failed to sign response: %v", err)
+			return
+		}
+		if err := msg.Respond([]byte(token)); err != nil {
+			log.Printf("Auth callout: failed to respond: %v", err)
+		}
+	}
+
+	sub, err := nc.Subscribe(AuthCalloutSubject, handle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", AuthCalloutSubject, err)
+	}
+	return sub, nil
+}
+
+// AuthCalloutConfig describes a server that delegates authentication to an
+// auth callout service.
+type AuthCalloutConfig struct {
+	Port            int
+	IssuerPublicKey string
+	AuthUser        string
+	AuthPassword    string
+	// Accounts callout users can be placed in, besides the auth account.
+	Accounts []string
+}
+
+// WriteAuthCalloutConfig writes a nats-server configuration with an
+// auth_callout block. The auth user lives in its own AUTH account and
+// bypasses the callout; every other client is authorized by the service.
+func WriteAuthCalloutConfig(filename string, cfg AuthCalloutConfig) error {
+	if !nkeys.IsValidPublicAccountKey(cfg.IssuerPublicKey) {
+		return fmt.Errorf("callout issuer %q is not an account public key", cfg.IssuerPublicKey)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	accounts := append([]string(nil), cfg.Accounts...)
+	if len(accounts) == 0 {
+		accounts = []string{DefaultCalloutAccount}
+	}
+	sort.Strings(accounts)
+
+	fmt.Fprintln(f, "# Generated Auth Callout Configuration")
+	fmt.Fprintln(f, "# Auto-generated - users are managed with `nats-demo callout useradd`")
+	fmt.Fprintln(f, "")
+	fmt.Fprintf(f, "port: %d\n", cfg.Port)
+	fmt.Fprintln(f, "")
+	fmt.Fprintln(f, "accounts {")
+	fmt.Fprintln(f, "  AUTH {")
+	fmt.Fprintf(f, "    users: [ {user: %q, password: %q} ]\n", cfg.AuthUser, cfg.AuthPassword)
+	fmt.Fprintln(f, "  }")
+	for _, name := range accounts {
+		fmt.Fprintf(f, "  %s {}\n", name)
+	}
+	fmt.Fprintln(f, "}")
+	fmt.Fprintln(f, "")
+	fmt.Fprintln(f, "authorization {")
+	fmt.Fprintln(f, "  auth_callout {")
+	fmt.Fprintf(f, "    issuer: %s\n", cfg.IssuerPublicKey)
+	fmt.Fprintln(f, "    account: AUTH")
+	fmt.Fprintf(f, "    auth_users: [ %q ]\n", cfg.AuthUser)
+	fmt.Fprintln(f, "  }")
+	fmt.Fprintln(f, "}")
+
+	return nil
+}
+
+// EnableAuthCallout adds an AUTH account with a single auth user to
+// server options loaded from an existing config, and sends every other
+// client to the callout. no_auth_user is cleared since anonymous clients
+// would otherwise bypass the callout.
+func EnableAuthCallout(opts *server.Options, issuerPublicKey, authUser, authPassword string) error {
+	if !nkeys.IsValidPublicAccountKey(issuerPublicKey) {
+		return fmt.Errorf("callout issuer %q is not an account public key", issuerPublicKey)
+	}
+
+	acc := server.NewAccount("AUTH")
+	opts.Accounts = append(opts.Accounts, acc)
+	opts.Users = append(opts.Users, &server.User{Username: authUser, Password: authPassword, Account: acc})
+	opts.NoAuthUser = ""
+	opts.AuthCallout = &server.AuthCallout{
+		Issuer:    issuerPublicKey,
+		Account:   acc.Name,
+		AuthUsers: []string{authUser},
+	}
+	return nil
+}
+
+// DemoAuthCallout runs an embedded server whose clients are authorized by
+// a callout service backed by a JSON user store.
+func DemoAuthCallout() {
+	fmt.Println("\n=== Auth Callout Demo ===")
+
+	dir, err := os.MkdirTemp("", "nats-callout-")
+	if err != nil {
+		log.Printf("Failed to create temp dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Println("\n1. Preparing the issuer key, user store and server config:")
+	issuer, err := nkeys.CreateAccount()
+	if err != nil {
+		log.Printf("Failed to create issuer key: %v", err)
+		return
+	}
+	issuerPub, _ := issuer.PublicKey()
+
+	roles, err := LoadRoleTemplates("config/basic-auth.conf")
+	if err != nil {
+		log.Printf("Failed to load role templates: %v", err)
+		return
+	}
+	fmt.Printf("✓ Loaded %d role templates from config/basic-auth.conf\n", len(roles))
+
+	store, err := LoadCalloutUserStore(filepath.Join(dir, "users.json"))
+	if err != nil {
+		log.Printf("Failed to load user store: %v", err)
+		return
+	}
+	users := []struct{ name, role, password, token string }{
+		{"alice", "ADMIN", "alice123", ""},
+		{"bob", "REQUESTOR", "bob123", ""},
+		{"svc", "RESPONDER", "", "svc-token-42"},
+	}
+	for _, u := range users {
+		if err := store.SetUser(u.name, u.role, "", u.password, u.token); err != nil {
+			log.Printf("Failed to add %s: %v", u.name, err)
+			return
+		}
+	}
+	if err := store.Save(); err != nil {
+		log.Printf("Failed to save user store: %v", err)
+		return
+	}
+	fmt.Printf("✓ User store has %d users (passwords and tokens bcrypt-hashed)\n", len(store.Users))
+
+	configFile := filepath.Join(dir, "auth-callout.conf")
+	err = WriteAuthCalloutConfig(configFile, AuthCalloutConfig{
+		Port:            -1,
+		IssuerPublicKey: issuerPub,
+		AuthUser:        "auth",
+		AuthPassword:    "auth-secret",
+	})
+	if err != nil {
+		log.Printf("Failed to write config: %v", err)
+		return
+	}
+	opts, err := server.ProcessConfigFile(configFile)
+	if err != nil {
+		log.Printf("Failed to load generated config: %v", err)
+		return
+	}
+	fmt.Println("✓ Generated auth_callout config")
+
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+
+	fmt.Println("\n2. Starting the callout service as the auth user:")
+	authConn, err := nats.Connect(url, userPassword("auth", "auth-secret"))
+	if err != nil {
+		log.Printf("Auth user connection failed: %v", err)
+		return
+	}
+	defer authConn.Close()
+
+	var mu sync.Mutex
+	callout := &AuthCallout{
+		Issuer:    issuer,
+		Store:     store,
+		Roles:     roles,
+		ExpiresIn: time.Hour,
+		Decisions: func(user string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fmt.Printf("  [callout] ✗ rejected %s: %v\n", user, err)
+			} else {
+				fmt.Printf("  [callout] ✓ accepted %s\n", user)
+			}
+		},
+	}
+	if _, err := callout.Start(authConn); err != nil {
+		log.Printf("Failed to start callout: %v", err)
+		return
+	}
+	authConn.Flush()
+	fmt.Printf("✓ Callout listening on %s\n", AuthCalloutSubject)
+
+	fmt.Println("\n3. Clients authorized by the callout:")
+	svc, err := nats.Connect(url, nats.Token("svc-token-42"))
+	if err != nil {
+		log.Printf("Service (token) connection failed: %v", err)
+		return
+	}
+	defer svc.Close()
+	fmt.Println("✓ Service connected with a token (RESPONDER role)")
+
+	svc.Subscribe("req.a", func(m *nats.Msg) {
+		m.Respond([]byte("response from svc"))
+	})
+	svc.Flush()
+
+	bob, err := nats.Connect(url, userPassword("bob", "bob123"))
+	if err != nil {
+		log.Printf("Bob connection failed: %v", err)
+		return
+	}
+	defer bob.Close()
+	fmt.Println("✓ Bob connected with a password (REQUESTOR role)")
+
+	if reply, err := bob.Request("req.a", []byte("hello"), 2*time.Second); err != nil {
+		log.Printf("Bob request failed: %v", err)
+	} else {
+		fmt.Printf("✓ Bob's request on 'req.a' answered: %s\n", string(reply.Data))
+	}
+
+	bobErrs := asyncErrors(bob)
+	bob.Publish("admin.shutdown", []byte("nope"))
+	bob.Flush()
+	if err := waitError(bobErrs, time.Second); err != nil {
+		fmt.Printf("✗ Bob correctly denied publishing to 'admin.shutdown': %v\n", err)
+	} else {
+		fmt.Println("❌ Bob was allowed to publish to 'admin.shutdown'")
+	}
+
+	alice, err := nats.Connect(url, userPassword("alice", "alice123"))
+	if err != nil {
+		log.Printf("Alice connection failed: %v", err)
+		return
+	}
+	defer alice.Close()
+	if err := alice.Publish("admin.shutdown", []byte("ok")); err == nil && alice.Flush() == nil {
+		fmt.Println("✓ Alice connected (ADMIN role) and published to 'admin.shutdown'")
+	}
+
+	fmt.Println("\n4. Clients rejected by the callout:")
+	if _, err := nats.Connect(url, userPassword("bob", "wrong")); err != nil {
+		fmt.Printf("✗ Wrong password rejected: %v\n", err)
+	}
+	if _, err := nats.Connect(url, userPassword("mallory", "x")); err != nil {
+		fmt.Printf("✗ Unknown user rejected: %v\n", err)
+	}
+
+	fmt.Println("\n=== Auth Callout Demo Complete ===")
+}
@@ -0,0 +1,572 @@
+package examples
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ServerConfig builds a nats-server configuration file with an
+// authorization block, in the same layout as the configs under config/.
+type ServerConfig struct {
+	// Header lines are written as comments at the top of the file.
+	Header []string
+	Port   int
+
+	// Token is a single shared token for every client. It cannot be combined
+	// with Users.
+	Token string
+	// HashSecrets writes the token and user passwords as bcrypt hashes.
+	HashSecrets bool
+
+	// Limits are the server-wide limits. MaxSubscriptions applies per
+	// connection.
+	Limits *ConfigLimits
+	// Mappings rewrite subjects published in the global account.
+	Mappings []ConfigMapping
+
+	DefaultPermissions *ConfigPermissions
+	Roles              []ConfigRole
+	Users              []ConfigUser
+
+	// Accounts are written to an accounts block. Roles are not visible
+	// inside accounts, so account users set Permissions directly.
+	Accounts []ConfigAccount
+	// NoAuthUser binds clients that send no credentials to this user.
+	NoAuthUser string
+	// SystemAccount names the account that receives server events.
+	SystemAccount string
+}
+
+// ConfigLimits are connection, subscription and payload limits. Zero
+// fields are left out.
+type ConfigLimits struct {
+	MaxConnections   int
+	MaxSubscriptions int
+	MaxPayload       int
+}
+
+// ConfigAccount is one account of the accounts block. Limits apply across
+// all of the account's connections.
+type ConfigAccount struct {
+	Name    string
+	Users   []ConfigUser
+	Limits  *ConfigLimits
+	Exports []ConfigExport
+	Imports []ConfigImport
+	// Mappings rewrite subjects published in the account.
+	Mappings []ConfigMapping
+}
+
+// ConfigMapping rewrites messages published on Subject. A single
+// destination takes every message; several split them by weight.
+type ConfigMapping struct {
+	Subject      string
+	Destinations []ConfigMappingDestination
+}
+
+// ConfigMappingDestination is a mapped subject and the percentage of
+// messages it receives. Zero weight on a sole destination means 100;
+// weights totalling less than 100 leave the rest on the original subject.
+type ConfigMappingDestination struct {
+	Subject string
+	Weight  int
+}
+
+// MapTo maps every message on subject to destination.
+func MapTo(subject, destination string) ConfigMapping {
+	return ConfigMapping{Subject: subject, Destinations: []ConfigMappingDestination{{Subject: destination}}}
+}
+
+// MappingWildcard is the transform inserting the nth wildcard token of the
+// published subject, counting from 1.
+func MappingWildcard(n int) string {
+	return fmt.Sprintf("{{wildcard(%d)}}", n)
+}
+
+// MappingPartition is the transform inserting a partition number in
+// [0, count), hashed from the given wildcard tokens, so messages with the
+// same tokens always land in the same partition.
+func MappingPartition(count int, wildcards ...int) string {
+	args := []string{fmt.Sprint(count)}
+	for _, w := range wildcards {
+		args = append(args, fmt.Sprint(w))
+	}
+	return fmt.Sprintf("{{partition(%s)}}", strings.Join(args, ","))
+}
+
+// ConfigExport is a stream or service export. Set either Stream or
+// Service to the exported subject. Accounts makes it private to the listed
+// accounts.
+type ConfigExport struct {
+	Stream   string
+	Service  string
+	Accounts []string
+	// Latency tracks requests to a service export.
+	Latency *ConfigLatency
+	// ResponseType is how many responses a service import forwards per
+	// request: ResponseSingleton (the default), ResponseStream or
+	// ResponseChunked.
+	ResponseType string
+	// ResponseThreshold is how long the server waits for a further
+	// response before dropping the reply mapping; zero keeps the server
+	// default of two minutes.
+	ResponseThreshold time.Duration
+}
+
+// Response types of a service export. Stream and chunked responses keep
+// flowing until an empty message or the response threshold.
+const (
+	ResponseSingleton = "singleton"
+	ResponseStream    = "stream"
+	ResponseChunked   = "chunked"
+)
+
+// ConfigLatency samples service requests and publishes a measurement for
+// each sampled request to Results, in the exporting account.
+type ConfigLatency struct {
+	// Sampling is the percentage of requests measured; zero means 100.
+	Sampling int
+	Results  string
+}
+
+// ConfigImport imports a stream or service from Account. Set either
+// Stream or Service to the subject the other account exports. Prefix
+// applies to streams, To to services.
+type ConfigImport struct {
+	Account string
+	Stream  string
+	Service string
+	Prefix  string
+	To      string
+}
+
+// ConfigPermissions are the publish and subscribe rules of a user or role.
+type ConfigPermissions struct {
+	Publish       []string
+	PublishDeny   []string
+	Subscribe     []string
+	SubscribeDeny []string
+}
+
+// ConfigRole is a named permission template, referenced by users as $NAME.
+type ConfigRole struct {
+	Name        string
+	Permissions ConfigPermissions
+}
+
+// ConfigUser is one entry of the users list. Set either User and Password
+// or NKey. Role names a ConfigRole; Permissions overrides it.
+type ConfigUser struct {
+	Comment     string
+	User        string
+	Password    string
+	NKey        string
+	Role        string
+	Permissions *ConfigPermissions
+}
+
+// HashSecret returns the bcrypt hash nats-server accepts in place of a
+// plaintext password or token.
+func HashSecret(secret string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash secret: %w", err)
+	}
+	return string(hash), nil
+}
+
+// IsHashedSecret reports whether s is already a bcrypt hash, of any
+// variant ($2a$, $2b$, $2y$).
+func IsHashedSecret(s string) bool {
+	_, err := bcrypt.Cost([]byte(s))
+	return err == nil
+}
+
+// Render returns the configuration text.
+func (c *ServerConfig) Render() ([]byte, error) {
+	if c.Token != "" && len(c.Users) > 0 {
+		return nil, fmt.Errorf("can not have a token and a users array")
+	}
+
+	var b bytes.Buffer
+	for _, line := range c.Header {
+		fmt.Fprintf(&b, "# %s\n", line)
+	}
+	if len(c.Header) > 0 {
+		fmt.Fprintln(&b, "")
+	}
+	fmt.Fprintf(&b, "port: %d\n", c.Port)
+	if c.Limits != nil {
+		fmt.Fprintln(&b, "")
+		writeConfigLimits(&b, "", *c.Limits)
+	}
+	if len(c.Mappings) > 0 {
+		fmt.Fprintln(&b, "")
+		if err := writeConfigMappings(&b, "", c.Mappings); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Token != "" || c.DefaultPermissions != nil || len(c.Roles) > 0 || len(c.Users) > 0 || len(c.Accounts) == 0 {
+		fmt.Fprintln(&b, "")
+		if err := c.writeAuthorization(&b); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.Accounts) > 0 {
+		fmt.Fprintln(&b, "")
+		fmt.Fprintln(&b, "accounts {")
+		for i, acc := range c.Accounts {
+			if i > 0 {
+				fmt.Fprintln(&b, "")
+			}
+			fmt.Fprintf(&b, "  %s {\n", acc.Name)
+			if err := c.writeUsers(&b, "    ", acc.Users); err != nil {
+				return nil, err
+			}
+			if acc.Limits != nil {
+				fmt.Fprintln(&b, "    limits {")
+				writeConfigLimits(&b, "      ", *acc.Limits)
+				fmt.Fprintln(&b, "    }")
+			}
+			if err := writeConfigExports(&b, acc.Exports); err != nil {
+				return nil, fmt.Errorf("account %s: %w", acc.Name, err)
+			}
+			if err := writeConfigImports(&b, acc.Imports); err != nil {
+				return nil, fmt.Errorf("account %s: %w", acc.Name, err)
+			}
+			if err := writeConfigMappings(&b, "    ", acc.Mappings); err != nil {
+				return nil, fmt.Errorf("account %s: %w", acc.Name, err)
+			}
+			fmt.Fprintln(&b, "  }")
+		}
+		fmt.Fprintln(&b, "}")
+	}
+
+	if c.NoAuthUser != "" {
+		fmt.Fprintf(&b, "\nno_auth_user: %s\n", c.NoAuthUser)
+	}
+	if c.SystemAccount != "" {
+		fmt.Fprintf(&b, "\nsystem_account: %s\n", c.SystemAccount)
+	}
+
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
+
+func (c *ServerConfig) writeAuthorization(b *bytes.Buffer) error {
+	fmt.Fprintln(b, "authorization {")
+
+	if c.Token != "" {
+		token, err := c.secret(c.Token)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "  token: %q\n", token)
+	}
+
+	if c.DefaultPermissions != nil {
+		writeConfigPermissions(b, "default_permissions", *c.DefaultPermissions)
+		fmt.Fprintln(b, "")
+	}
+	for _, role := range c.Roles {
+		writeConfigPermissions(b, role.Name, role.Permissions)
+		fmt.Fprintln(b, "")
+	}
+
+	if err := c.writeUsers(b, "  ", c.Users); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(b, "}")
+	return nil
+}
+
+func (c *ServerConfig) writeUsers(b *bytes.Buffer, indent string, users []ConfigUser) error {
+	if len(users) == 0 {
+		return nil
+	}
+	fmt.Fprintf(b, "%susers = [\n", indent)
+	for i, u := range users {
+		entry, err := c.userEntry(u)
+		if err != nil {
+			return err
+		}
+		if u.Comment != "" {
+			fmt.Fprintf(b, "%s  # %s\n", indent, u.Comment)
+		}
+		fmt.Fprintf(b, "%s  %s", indent, entry)
+		if i < len(users)-1 {
+			fmt.Fprintln(b, ",")
+		} else {
+			fmt.Fprintln(b, "")
+		}
+	}
+	fmt.Fprintf(b, "%s]\n", indent)
+	return nil
+}
+
+// writeConfigLimits writes the non-zero limits, one per line.
+func writeConfigLimits(b *bytes.Buffer, indent string, l ConfigLimits) {
+	if l.MaxConnections > 0 {
+		fmt.Fprintf(b, "%smax_connections: %d\n", indent, l.MaxConnections)
+	}
+	if l.MaxSubscriptions > 0 {
+		fmt.Fprintf(b, "%smax_subscriptions: %d\n", indent, l.MaxSubscriptions)
+	}
+	if l.MaxPayload > 0 {
+		fmt.Fprintf(b, "%smax_payload: %d\n", indent, l.MaxPayload)
+	}
+}
+
+// writeConfigExports writes an account's exports list, one export per line.
+func writeConfigExports(b *bytes.Buffer, exports []ConfigExport) error {
+	if len(exports) == 0 {
+		return nil
+	}
+	fmt.Fprintln(b, "    exports = [")
+	for _, e := range exports {
+		var fields []string
+		switch {
+		case (e.Stream == "") == (e.Service == ""):
+			return fmt.Errorf("export needs exactly one of a stream or a service")
+		case e.Stream != "" && e.Latency != nil:
+			return fmt.Errorf("latency tracking applies to services, not stream %s", e.Stream)
+		case e.Stream != "" && (e.ResponseType != "" || e.ResponseThreshold > 0):
+			return fmt.Errorf("response types apply to services, not stream %s", e.Stream)
+		case e.Stream != "":
+			fields = append(fields, fmt.Sprintf("stream: %q", e.Stream))
+		default:
+			fields = append(fields, fmt.Sprintf("service: %q", e.Service))
+		}
+		if len(e.Accounts) > 0 {
+			fields = append(fields, "accounts: "+configAccountList(e.Accounts))
+		}
+		if l := e.Latency; l != nil {
+			if l.Results == "" {
+				return fmt.Errorf("latency for %s needs a results subject", e.Service)
+			}
+			sampling := l.Sampling
+			if sampling == 0 {
+				sampling = 100
+			}
+			if sampling < 1 || sampling > 100 {
+				return fmt.Errorf("latency sampling for %s must be 1-100%%, got %d", e.Service, sampling)
+			}
+			fields = append(fields, fmt.Sprintf("latency: {sampling: \"%d%%\", subject: %q}", sampling, l.Results))
+		}
+		switch e.ResponseType {
+		case "":
+		case ResponseSingleton, ResponseStream, ResponseChunked:
+			fields = append(fields, "response_type: "+e.ResponseType)
+		default:
+			return fmt.Errorf("unknown response type %q for %s", e.ResponseType, e.Service)
+		}
+		if e.ResponseThreshold > 0 {
+			fields = append(fields, fmt.Sprintf("response_threshold: %q", e.ResponseThreshold))
+		}
+		fmt.Fprintf(b, "      {%s}\n", strings.Join(fields, ", "))
+	}
+	fmt.Fprintln(b, "    ]")
+	return nil
+}
+
+// writeConfigImports writes an account's imports list, one import per line.
+func writeConfigImports(b *bytes.Buffer, imports []ConfigImport) error {
+	if len(imports) == 0 {
+		return nil
+	}
+	fmt.Fprintln(b, "    imports = [")
+	for _, i := range imports {
+		var entry string
+		switch {
+		case i.Account == "":
+			return fmt.Errorf("import needs an account")
+		case (i.Stream == "") == (i.Service == ""):
+			return fmt.Errorf("import from %s needs exactly one of a stream or a service", i.Account)
+		case i.Stream != "":
+			if i.To != "" {
+				return fmt.Errorf("stream import of %s uses a prefix, not to", i.Stream)
+			}
+			entry = fmt.Sprintf("stream: {account: %s, subject: %q}", i.Account, i.Stream)
+			if i.Prefix != "" {
+				entry += fmt.Sprintf(", prefix: %q", i.Prefix)
+			}
+		default:
+			if i.Prefix != "" {
+				return fmt.Errorf("service import of %s uses to, not a prefix", i.Service)
+			}
+			entry = fmt.Sprintf("service: {account: %s, subject: %q}", i.Account, i.Service)
+			if i.To != "" {
+				entry += fmt.Sprintf(", to: %q", i.To)
+			}
+		}
+		fmt.Fprintf(b, "      {%s}\n", entry)
+	}
+	fmt.Fprintln(b, "    ]")
+	return nil
+}
+
+// writeConfigMappings writes a mappings block. A mapping with one full
+// destination is a plain string; weighted ones are lists.
+func writeConfigMappings(b *bytes.Buffer, indent string, mappings []ConfigMapping) error {
+	if len(mappings) == 0 {
+		return nil
+	}
+	fmt.Fprintf(b, "%smappings = {\n", indent)
+	for _, m := range mappings {
+		if len(m.Destinations) == 0 {
+			return fmt.Errorf("mapping of %s needs a destination", m.Subject)
+		}
+		total := 0
+		for _, d := range m.Destinations {
+			if d.Weight < 0 || d.Weight > 100 {
+				return fmt.Errorf("mapping of %s to %s: weight must be 0-100%%, got %d", m.Subject, d.Subject, d.Weight)
+			}
+			total += d.Weight
+		}
+		if total > 100 {
+			return fmt.Errorf("mapping of %s: weights add up to %d%%, more than 100%%", m.Subject, total)
+		}
+
+		if d := m.Destinations[0]; len(m.Destinations) == 1 && (d.Weight == 0 || d.Weight == 100) {
+			fmt.Fprintf(b, "%s  %q: %q\n", indent, m.Subject, d.Subject)
+			continue
+		}
+		fmt.Fprintf(b, "%s  %q: [\n", indent, m.Subject)
+		for _, d := range m.Destinations {
+			if d.Weight == 0 {
+				return fmt.Errorf("mapping of %s to %s needs a weight", m.Subject, d.Subject)
+			}
+			fmt.Fprintf(b, "%s    {destination: %q, weight: \"%d%%\"}\n", indent, d.Subject, d.Weight)
+		}
+		fmt.Fprintf(b, "%s  ]\n", indent)
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+	return nil
+}
+
+func configAccountList(accounts []string) string {
+	return "[" + strings.Join(accounts, ", ") + "]"
+}
+
+// WriteFile renders the configuration to filename.
+func (c *ServerConfig) WriteFile(filename string) error {
+	data, err := c.Render()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// secret hashes s when HashSecrets is set, leaving existing hashes as they
+// are.
+func (c *ServerConfig) secret(s string) (string, error) {
+	if !c.HashSecrets || IsHashedSecret(s) {
+		return s, nil
+	}
+	return HashSecret(s)
+}
+
+func (c *ServerConfig) userEntry(u ConfigUser) (string, error) {
+	var fields []string
+	switch {
+	case u.NKey != "":
+		fields = append(fields, fmt.Sprintf("nkey: %q", u.NKey))
+	case u.User != "":
+		password, err := c.secret(u.Password)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, fmt.Sprintf("user: %s", u.User), fmt.Sprintf("password: %q", password))
+	default:
+		return "", fmt.Errorf("config user needs a user or an nkey")
+	}
+
+	switch {
+	case u.Permissions != nil:
+		fields = append(fields, "permissions: "+inlineConfigPermissions(*u.Permissions))
+	case u.Role != "":
+		fields = append(fields, "permissions: $"+u.Role)
+	}
+	return "{" + strings.Join(fields, ", ") + "}", nil
+}
+
+func writeConfigPermissions(b *bytes.Buffer, name string, p ConfigPermissions) {
+	fmt.Fprintf(b, "  %s = {\n", name)
+	if v := configPermissionValue(p.Publish, p.PublishDeny); v != "" {
+		fmt.Fprintf(b, "    publish = %s\n", v)
+	}
+	if v := configPermissionValue(p.Subscribe, p.SubscribeDeny); v != "" {
+		fmt.Fprintf(b, "    subscribe = %s\n", v)
+	}
+	fmt.Fprintln(b, "  }")
+}
+
+func inlineConfigPermissions(p ConfigPermissions) string {
+	var parts []string
+	if v := configPermissionValue(p.Publish, p.PublishDeny); v != "" {
+		parts = append(parts, "publish: "+v)
+	}
+	if v := configPermissionValue(p.Subscribe, p.SubscribeDeny); v != "" {
+		parts = append(parts, "subscribe: "+v)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// configPermissionValue renders a subject, a subject list, or an
+// allow/deny map when deny rules are present.
+func configPermissionValue(allow, deny []string) string {
+	if len(deny) == 0 {
+		return configSubjectList(allow)
+	}
+	var parts []string
+	if len(allow) > 0 {
+		parts = append(parts, "allow: "+configSubjectList(allow))
+	}
+	parts = append(parts, "deny: "+configSubjectList(deny))
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func configSubjectList(subjects []string) string {
+	switch len(subjects) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("%q", subjects[0])
+	}
+	quoted := make([]string, len(subjects))
+	for i, s := range subjects {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// demoRoles are the ADMIN, REQUESTOR and RESPONDER templates used across
+// the demo configs.
+func demoRoles() []ConfigRole {
+	return []ConfigRole{
+		{"ADMIN", ConfigPermissions{Publish: []string{">"}, Subscribe: []string{">"}}},
+		{"REQUESTOR", ConfigPermissions{Publish: []string{"req.a", "req.b"}, Subscribe: []string{"_INBOX.>"}}},
+		{"RESPONDER", ConfigPermissions{Publish: []string{"_INBOX.>"}, Subscribe: []string{"req.a", "req.b"}}},
+	}
+}
+
+// demoDefaultPermissions are the default_permissions used across the demo
+// configs.
+func demoDefaultPermissions() *ConfigPermissions {
+	return &ConfigPermissions{Publish: []string{"SANDBOX.*"}, Subscribe: []string{"PUBLIC.>", "_INBOX.>"}}
+}
@@ -0,0 +1,126 @@
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/nats-io/nats.go"
+)
+
+// DefaultProfilesFile holds the connection profiles for the demo servers.
+const DefaultProfilesFile = "config/profiles.json"
+
+// ConnectionProfile describes how a client reaches and authenticates to a
+// server. Exactly one of user/password, token, nkey seed or creds is set.
+// Password, Token and NKeySeed accept secret references.
+type ConnectionProfile struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+	NKeySeed string `json:"nkey_seed,omitempty"`
+	Creds    string `json:"creds,omitempty"`
+
+	// TokenSource, if set, is asked for the token on every connect and
+	// reconnect instead of using Token.
+	TokenSource func() string `json:"-"`
+}
+
+// LoadConnectionProfiles reads profiles from a JSON file, keyed by name.
+func LoadConnectionProfiles(filename string) (map[string]*ConnectionProfile, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var file struct {
+		Profiles []*ConnectionProfile `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles: %w", err)
+	}
+
+	profiles := make(map[string]*ConnectionProfile, len(file.Profiles))
+	for _, p := range file.Profiles {
+		if _, ok := profiles[p.Name]; ok {
+			return nil, fmt.Errorf("duplicate profile %q", p.Name)
+		}
+		profiles[p.Name] = p
+	}
+	return profiles, nil
+}
+
+// ProfileNames returns the profile names, sorted.
+func ProfileNames(profiles map[string]*ConnectionProfile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthMethod names the way the profile authenticates.
+func (p *ConnectionProfile) AuthMethod() string {
+	switch {
+	case p.TokenSource != nil:
+		return "token (handler)"
+	case p.Token != "" && DefaultSecrets.IsReference(p.Token):
+		return "token (rotating)"
+	case p.Token != "":
+		return "token"
+	case p.NKeySeed != "":
+		return "nkey"
+	case p.Creds != "":
+		return "creds"
+	case p.User != "":
+		return "user/password"
+	}
+	return "none"
+}
+
+// Options returns the nats options for the profile's authentication.
+func (p *ConnectionProfile) Options() ([]nats.Option, error) {
+	methods := 0
+	for _, set := range []bool{p.User != "", p.Token != "" || p.TokenSource != nil, p.NKeySeed != "", p.Creds != ""} {
+		if set {
+			methods++
+		}
+	}
+	if methods > 1 {
+		return nil, fmt.Errorf("profile %q sets more than one authentication method", p.Name)
+	}
+
+	opts := []nats.Option{nats.Name(p.Name)}
+	switch {
+	case p.TokenSource != nil:
+		opts = append(opts, nats.TokenHandler(p.TokenSource))
+	case p.Token != "":
+		opts = append(opts, tokenOption(p.Token))
+	case p.NKeySeed != "":
+		opts = append(opts, nkeyOption(p.NKeySeed))
+	case p.Creds != "":
+		opts = append(opts, nats.UserCredentials(p.Creds))
+	case p.User != "":
+		opts = append(opts, userPassword(p.User, p.Password))
+	}
+	return opts, nil
+}
+
+// Connect connects with the profile. extra options are applied after the
+// profile's own.
+func (p *ConnectionProfile) Connect(extra ...nats.Option) (*nats.Conn, error) {
+	opts, err := p.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	nc, err := nats.Connect(p.URL, append(opts, extra...)...)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", p.Name, err)
+	}
+	return nc, nil
+}
@@ -0,0 +1,143 @@
+package examples
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// DemoConnectionTypes demonstrates allowed_connection_types: backend,
+// browser and device users can each connect only over the standard, the
+// WebSocket or the MQTT listener
+func DemoConnectionTypes() {
+	fmt.Println("\n=== Allowed Connection Types Demo ===")
+
+	opts, err := server.ProcessConfigFile("config/connection-types.conf")
+	if err != nil {
+		log.Printf("Failed to load config/connection-types.conf: %v", err)
+		return
+	}
+	storeDir, err := os.MkdirTemp("", "nats-conn-types-")
+	if err != nil {
+		log.Printf("Failed to create JetStream dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(storeDir)
+	opts.StoreDir = storeDir
+	opts.Port = server.RANDOM_PORT
+	opts.Websocket.Port = server.RANDOM_PORT
+	opts.MQTT.Port = server.RANDOM_PORT
+
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return
+	}
+	defer s.Shutdown()
+
+	natsURL := s.ClientURL()
+	wsURL := fmt.Sprintf("ws://127.0.0.1:%d", opts.Websocket.Port)
+	mqttAddr := net.JoinHostPort("127.0.0.1", strconv.Itoa(opts.MQTT.Port))
+	fmt.Printf("\n✓ Listeners: standard %s, WebSocket %s, MQTT %s\n", natsURL, wsURL, mqttAddr)
+
+	fmt.Println("\n1. Each user on its own channel:")
+	backend, err := nats.Connect(natsURL, userPassword("backend", "backend123"))
+	if err != nil {
+		log.Printf("Backend connection failed: %v", err)
+		return
+	}
+	defer backend.Close()
+	fmt.Println("✓ backend connected over the standard protocol")
+
+	browser, err := nats.Connect(wsURL, userPassword("browser", "browser123"))
+	if err != nil {
+		log.Printf("Browser connection failed: %v", err)
+		return
+	}
+	defer browser.Close()
+	fmt.Println("✓ browser connected over WebSocket (Go client, ws:// URL)")
+
+	device, err := DialMQTT(mqttAddr, "sensor-42", "device", "device123")
+	if err != nil {
+		log.Printf("Device connection failed: %v", err)
+		return
+	}
+	defer device.Close()
+	fmt.Println("✓ device connected over MQTT")
+
+	fmt.Println("\n2. Messages crossing channels:")
+	backendSub, _ := backend.SubscribeSync("sensors.temp")
+	browserSub, _ := browser.SubscribeSync("sensors.temp")
+	backend.Flush()
+	browser.Flush()
+	if err := device.Subscribe("commands/sensor-42"); err != nil {
+		log.Printf("Device subscribe failed: %v", err)
+		return
+	}
+
+	if err := device.Publish("sensors/temp", []byte("21.5")); err != nil {
+		log.Printf("Device publish failed: %v", err)
+		return
+	}
+	if msg, err := backendSub.NextMsg(2 * time.Second); err == nil {
+		fmt.Printf("✓ backend received MQTT topic sensors/temp as %s: %s\n", msg.Subject, msg.Data)
+	} else {
+		fmt.Printf("❌ backend did not receive the reading: %v\n", err)
+	}
+	if msg, err := browserSub.NextMsg(2 * time.Second); err == nil {
+		fmt.Printf("✓ browser received it over WebSocket: %s\n", msg.Data)
+	} else {
+		fmt.Printf("❌ browser did not receive the reading: %v\n", err)
+	}
+
+	backend.Publish("commands.sensor-42", []byte("recalibrate"))
+	backend.Flush()
+	if topic, payload, err := device.ReadMessage(2 * time.Second); err == nil {
+		fmt.Printf("✓ device received NATS subject commands.sensor-42 as topic %s: %s\n", topic, payload)
+	} else {
+		fmt.Printf("❌ device did not receive the command: %v\n", err)
+	}
+
+	fmt.Println("\n3. Users on the wrong channel are refused:")
+	refusals := []struct {
+		label string
+		url   string
+		user  string
+		pass  string
+	}{
+		{"STANDARD-only backend on the WebSocket port", wsURL, "backend", "backend123"},
+		{"WEBSOCKET-only browser on the standard port", natsURL, "browser", "browser123"},
+		{"MQTT-only device on the standard port", natsURL, "device", "device123"},
+		{"MQTT-only device on the WebSocket port", wsURL, "device", "device123"},
+	}
+	for _, r := range refusals {
+		if nc, err := nats.Connect(r.url, userPassword(r.user, r.pass)); err != nil {
+			if errors.Is(err, io.EOF) {
+				// Over WebSocket the client sees the close, not the -ERR.
+				err = fmt.Errorf("%w (server closed the unauthorized WebSocket connection)", err)
+			}
+			fmt.Printf("✗ %s: %v\n", r.label, err)
+		} else {
+			fmt.Printf("❌ %s was accepted\n", r.label)
+			nc.Close()
+		}
+	}
+	for _, user := range []struct{ name, pass string }{{"backend", "backend123"}, {"browser", "browser123"}} {
+		if c, err := DialMQTT(mqttAddr, "intruder-"+user.name, user.name, user.pass); err != nil {
+			fmt.Printf("✗ %s over MQTT: %v\n", user.name, err)
+		} else {
+			fmt.Printf("❌ %s was accepted over MQTT\n", user.name)
+			c.Close()
+		}
+	}
+
+	fmt.Println("\n=== Allowed Connection Types Demo Complete ===")
+}
@@ -0,0 +1,60 @@
+package examples
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// startEmbeddedServer runs an in-process NATS server with the given options
+// and waits until it is ready to accept client connections. A zero port is
+// replaced with a random free port; use ClientURL() to find it.
+func startEmbeddedServer(opts *server.Options) (*server.Server, error) {
+	if opts.Host == "" {
+		opts.Host = "127.0.0.1"
+	}
+	if opts.Port == 0 {
+		opts.Port = server.RANDOM_PORT
+	}
+	opts.NoSigs = true
+	opts.NoLog = true
+
+	s, err := server.NewServer(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedded server: %w", err)
+	}
+
+	go s.Start()
+
+	if !s.ReadyForConnections(5 * time.Second) {
+		s.Shutdown()
+		return nil, fmt.Errorf("embedded server did not become ready")
+	}
+
+	return s, nil
+}
+
+// asyncErrors collects the async errors, such as permission violations,
+// the server reports on a connection.
+func asyncErrors(nc *nats.Conn) <-chan error {
+	errs := make(chan error, 16)
+	nc.SetErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	return errs
+}
+
+// waitError returns the next error from errs, or nil after timeout.
+func waitError(errs <-chan error, timeout time.Duration) error {
+	select {
+	case err := <-errs:
+		return err
+	case <-time.After(timeout):
+		return nil
+	}
+}
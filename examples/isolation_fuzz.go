@@ -0,0 +1,442 @@
+package examples
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// IsolationFuzzOptions control FuzzAccountIsolation. Zero values pick the
+// defaults.
+type IsolationFuzzOptions struct {
+	// Seed drives every random choice; zero picks one from the clock. The
+	// report records the seed used, so a failing run can be repeated.
+	Seed int64
+	// Subjects is how many random subjects each account publishes; default 30.
+	Subjects int
+	// Patterns is how many random wildcard subscriptions each account adds
+	// to its ">" subscription; default 3, negative for none.
+	Patterns int
+	// Idle is how long to wait for further deliveries; default 500ms.
+	Idle time.Duration
+}
+
+// AccountRoute is where a message published in one account arrives: the
+// account, the subject it arrives on there, and the imports it crossed.
+type AccountRoute struct {
+	Account string
+	Subject string
+	Via     []GraphEdge
+}
+
+// Path describes the imports crossed in the direction the message
+// travelled, or "local".
+func (r AccountRoute) Path() string {
+	if len(r.Via) == 0 {
+		return "local"
+	}
+	var hops []string
+	for _, e := range r.Via {
+		from, to := e.From, e.To
+		if e.Kind == "service" {
+			from, to = to, from
+		}
+		hop := fmt.Sprintf("%s %s->%s %s", e.Kind, from, to, e.Subject)
+		switch {
+		case e.Prefix != "":
+			hop += " prefix " + e.Prefix
+		case e.Remap != "":
+			hop += " to " + e.Remap
+		}
+		hops = append(hops, hop)
+	}
+	return strings.Join(hops, ", ")
+}
+
+// Routes lists where a message published on subject in account arrives,
+// following authorized imports transitively: stream imports carry it from
+// the exporter to the importer (under the prefix, if any), and service
+// imports carry it from the importer to the exporter. The first route is
+// the publishing account itself.
+func (g *AccountGraph) Routes(account, subject string) []AccountRoute {
+	routes := []AccountRoute{{Account: account, Subject: subject}}
+	seen := map[string]bool{account + " " + subject: true}
+	for i := 0; i < len(routes); i++ {
+		r := routes[i]
+		for _, e := range g.Edges {
+			if !e.Authorized() {
+				continue
+			}
+			var next AccountRoute
+			switch {
+			case e.Kind == "stream" && e.From == r.Account && subjectCovers(e.Subject, r.Subject):
+				next = AccountRoute{Account: e.To, Subject: r.Subject}
+				if e.Prefix != "" {
+					next.Subject = e.Prefix + "." + r.Subject
+				} else if e.Remap != "" {
+					next.Subject = e.Remap
+				}
+			case e.Kind == "service" && e.To == r.Account && subjectCovers(e.LocalSubject(), r.Subject):
+				next = AccountRoute{Account: e.From, Subject: r.Subject}
+				if e.Remap != "" {
+					next.Subject = e.Subject
+				}
+			default:
+				continue
+			}
+			// Remaps with wildcards need the server's transforms; leave
+			// them unexplained rather than guess.
+			if strings.ContainsAny(next.Subject, "*>") {
+				continue
+			}
+			key := next.Account + " " + next.Subject
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			next.Via = append(append([]GraphEdge{}, r.Via...), e)
+			routes = append(routes, next)
+		}
+	}
+	return routes
+}
+
+// IsolationDelivery is one message a subscription received, or should
+// have received.
+type IsolationDelivery struct {
+	Publisher string
+	Published string
+	Account   string
+	Pattern   string
+	Received  string
+	// Path is the route that explains the delivery; empty when none does.
+	Path string
+}
+
+func (d IsolationDelivery) String() string {
+	s := fmt.Sprintf("%s published %s -> %s %q received %s", d.Publisher, d.Published, d.Account, d.Pattern, d.Received)
+	if d.Path != "" {
+		s += " via " + d.Path
+	}
+	return s
+}
+
+// IsolationReport is the outcome of FuzzAccountIsolation.
+type IsolationReport struct {
+	Seed     int64
+	Accounts []string
+	// Skipped are accounts without a password user to connect as.
+	Skipped       []string
+	Subjects      []string
+	Subscriptions map[string][]string
+	Published     int
+	Local         int
+	// CrossAccount counts the explained cross-account deliveries by path.
+	CrossAccount map[string]int
+	// Unexplained deliveries crossed accounts without an import allowing it.
+	Unexplained []IsolationDelivery
+	// Missing deliveries were explained by an import but never arrived.
+	Missing []IsolationDelivery
+}
+
+// OK reports whether every delivery matched the declared imports.
+func (r *IsolationReport) OK() bool {
+	return len(r.Unexplained) == 0 && len(r.Missing) == 0
+}
+
+// isolationVocabulary is the tokens random subjects are built from: every
+// literal token of the config's export and import subjects, prefixes and
+// remaps, plus some the config does not mention.
+func isolationVocabulary(g *AccountGraph) ([]string, []string) {
+	tokens := map[string]bool{"data": true, "orders": true, "private": true, "events": true, "x": true}
+	patterns := map[string]bool{}
+	add := func(subject string) {
+		if subject == "" {
+			return
+		}
+		patterns[subject] = true
+		for _, t := range strings.Split(subject, ".") {
+			if t != "*" && t != ">" {
+				tokens[t] = true
+			}
+		}
+	}
+	for _, acc := range g.Accounts {
+		for _, e := range acc.Exports {
+			add(e.Subject)
+		}
+	}
+	for _, e := range g.Edges {
+		add(e.Subject)
+		add(e.LocalSubject())
+	}
+	return sortedKeys(tokens), sortedKeys(patterns)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// randomSubjects builds n distinct subjects. Half instantiate a config
+// subject, so imports are exercised; the rest are random paths.
+func randomSubjects(rng *rand.Rand, n int, tokens, patterns []string) []string {
+	token := func() string { return tokens[rng.Intn(len(tokens))] }
+	seen := map[string]bool{}
+	var subjects []string
+	for attempts := 0; len(subjects) < n && attempts < n*20; attempts++ {
+		var parts []string
+		if rng.Intn(2) == 0 {
+			for _, t := range strings.Split(patterns[rng.Intn(len(patterns))], ".") {
+				switch t {
+				case "*":
+					parts = append(parts, token())
+				case ">":
+					for i := 0; i <= rng.Intn(2); i++ {
+						parts = append(parts, token())
+					}
+				default:
+					parts = append(parts, t)
+				}
+			}
+		} else {
+			for i := 0; i <= rng.Intn(4); i++ {
+				parts = append(parts, token())
+			}
+		}
+		subject := strings.Join(parts, ".")
+		if !seen[subject] {
+			seen[subject] = true
+			subjects = append(subjects, subject)
+		}
+	}
+	return subjects
+}
+
+// randomPattern turns subject into a wildcard subscription by replacing
+// one token with "*" or cutting it short with ">" after the first token.
+func randomPattern(rng *rand.Rand, subject string) string {
+	parts := strings.Split(subject, ".")
+	i := rng.Intn(len(parts))
+	if i == 0 || rng.Intn(2) == 0 {
+		parts[i] = "*"
+		return strings.Join(parts, ".")
+	}
+	return strings.Join(append(parts[:i], ">"), ".")
+}
+
+// FuzzAccountIsolation runs configFile in an embedded server and, in every
+// account, subscribes to ">" and some random wildcard subjects, then
+// publishes random subjects from every account. Each delivery is checked
+// against AccountGraph.Routes: only the publisher's own account and the
+// accounts its imports and exports lead to may receive the message.
+func FuzzAccountIsolation(configFile string, opts IsolationFuzzOptions) (*IsolationReport, error) {
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	if opts.Subjects <= 0 {
+		opts.Subjects = 30
+	}
+	if opts.Patterns < 0 {
+		opts.Patterns = 0
+	} else if opts.Patterns == 0 {
+		opts.Patterns = 3
+	}
+	if opts.Idle <= 0 {
+		opts.Idle = 500 * time.Millisecond
+	}
+
+	g, err := LoadAccountGraph(configFile)
+	if err != nil {
+		return nil, err
+	}
+	serverOpts, err := server.ProcessConfigFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", configFile, err)
+	}
+	serverOpts.Port = server.RANDOM_PORT
+	s, err := startEmbeddedServer(serverOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Shutdown()
+
+	report := &IsolationReport{Seed: opts.Seed, Subscriptions: map[string][]string{}, CrossAccount: map[string]int{}}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	tokens, patterns := isolationVocabulary(g)
+	report.Subjects = randomSubjects(rng, opts.Subjects, tokens, patterns)
+
+	type listener struct {
+		account, pattern string
+		sub              *nats.Subscription
+	}
+	conns := map[string]*nats.Conn{}
+	var listeners []listener
+	for _, acc := range g.Accounts {
+		var user *server.User
+		for _, u := range serverOpts.Users {
+			if u.Account != nil && u.Account.Name == acc.Name && u.Password != "" {
+				user = u
+				break
+			}
+		}
+		if user == nil {
+			report.Skipped = append(report.Skipped, acc.Name)
+			continue
+		}
+		nc, err := nats.Connect(s.ClientURL(), userPassword(user.Username, user.Password))
+		if err != nil {
+			return nil, fmt.Errorf("account %s connection failed: %w", acc.Name, err)
+		}
+		defer nc.Close()
+		conns[acc.Name] = nc
+		report.Accounts = append(report.Accounts, acc.Name)
+
+		subs := []string{">"}
+		for i := 0; i < opts.Patterns; i++ {
+			pattern := randomPattern(rng, report.Subjects[rng.Intn(len(report.Subjects))])
+			if !containsString(subs, pattern) {
+				subs = append(subs, pattern)
+			}
+		}
+		for _, pattern := range subs {
+			sub, err := nc.SubscribeSync(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("account %s failed to subscribe to %s: %w", acc.Name, pattern, err)
+			}
+			sub.SetPendingLimits(-1, -1)
+			listeners = append(listeners, listener{acc.Name, pattern, sub})
+			report.Subscriptions[acc.Name] = append(report.Subscriptions[acc.Name], pattern)
+		}
+		if err := nc.Flush(); err != nil {
+			return nil, fmt.Errorf("account %s failed to flush subscriptions: %w", acc.Name, err)
+		}
+	}
+
+	// Each message carries the seed and its index, which identifies the
+	// publisher and subject and tells fuzz messages from server events.
+	type publish struct{ account, subject string }
+	var published []publish
+	marker := fmt.Sprintf("isolation-fuzz %d", opts.Seed)
+	for _, account := range report.Accounts {
+		for _, subject := range report.Subjects {
+			payload := fmt.Sprintf("%s %d", marker, len(published))
+			if err := conns[account].Publish(subject, []byte(payload)); err != nil {
+				return nil, fmt.Errorf("account %s failed to publish %s: %w", account, subject, err)
+			}
+			published = append(published, publish{account, subject})
+		}
+		conns[account].Flush()
+	}
+	report.Published = len(published)
+
+	expected := map[string]IsolationDelivery{}
+	for i, p := range published {
+		for _, r := range g.Routes(p.account, p.subject) {
+			for j, l := range listeners {
+				if l.account == r.Account && subjectCovers(l.pattern, r.Subject) {
+					expected[fmt.Sprintf("%d %d %s", i, j, r.Subject)] = IsolationDelivery{
+						Publisher: p.account, Published: p.subject, Account: l.account,
+						Pattern: l.pattern, Received: r.Subject, Path: r.Path(),
+					}
+				}
+			}
+		}
+	}
+
+	for j, l := range listeners {
+		for {
+			msg, err := l.sub.NextMsg(opts.Idle)
+			if err != nil {
+				break
+			}
+			var i int
+			if !strings.HasPrefix(string(msg.Data), marker+" ") {
+				continue
+			}
+			if _, err := fmt.Sscanf(strings.TrimPrefix(string(msg.Data), marker+" "), "%d", &i); err != nil || i >= len(published) {
+				continue
+			}
+			key := fmt.Sprintf("%d %d %s", i, j, msg.Subject)
+			d, ok := expected[key]
+			switch {
+			case !ok:
+				report.Unexplained = append(report.Unexplained, IsolationDelivery{
+					Publisher: published[i].account, Published: published[i].subject,
+					Account: l.account, Pattern: l.pattern, Received: msg.Subject,
+				})
+				continue
+			case d.Path == "local":
+				report.Local++
+			default:
+				report.CrossAccount[d.Path]++
+			}
+			delete(expected, key)
+		}
+	}
+	for _, d := range expected {
+		report.Missing = append(report.Missing, d)
+	}
+	sort.Slice(report.Missing, func(i, j int) bool { return report.Missing[i].String() < report.Missing[j].String() })
+	return report, nil
+}
+
+// DemoAccountIsolationFuzz demonstrates checking account isolation with
+// random subjects from every account of config/accounts.conf
+func DemoAccountIsolationFuzz() {
+	fmt.Println("\n=== Account Isolation Fuzzer Demo ===")
+
+	report, err := FuzzAccountIsolation("config/accounts.conf", IsolationFuzzOptions{})
+	if err != nil {
+		log.Printf("Isolation fuzzing failed: %v", err)
+		return
+	}
+
+	fmt.Printf("\n1. Seed %d: %d random subjects, e.g.:\n", report.Seed, len(report.Subjects))
+	for _, subject := range report.Subjects[:min(8, len(report.Subjects))] {
+		fmt.Printf("   %s\n", subject)
+	}
+
+	fmt.Println("\n2. Wildcard subscriptions per account:")
+	for _, account := range report.Accounts {
+		fmt.Printf("   %-4s %s\n", account, strings.Join(report.Subscriptions[account], "  "))
+	}
+	for _, account := range report.Skipped {
+		fmt.Printf("   %-4s skipped: no password user\n", account)
+	}
+
+	fmt.Printf("\n3. Published %d messages (every subject from every account):\n", report.Published)
+	fmt.Printf("✓ %d deliveries within the publishing account\n", report.Local)
+	var paths []string
+	for path := range report.CrossAccount {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Printf("✓ %d cross-account deliveries via %s\n", report.CrossAccount[path], path)
+	}
+	for _, d := range report.Unexplained {
+		fmt.Printf("❌ Unexplained: %s\n", d)
+	}
+	for _, d := range report.Missing {
+		fmt.Printf("❌ Missing: %s\n", d)
+	}
+
+	if report.OK() {
+		fmt.Println("\n✓ Every cross-account delivery is explained by a declared export and import")
+	} else {
+		fmt.Printf("\n✗ Isolation check failed; reproduce with: nats-demo fuzz config/accounts.conf --seed %d\n", report.Seed)
+	}
+
+	fmt.Println("\n=== Account Isolation Fuzzer Demo Complete ===")
+}
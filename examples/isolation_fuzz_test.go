@@ -0,0 +1,64 @@
+package examples
+
+import "testing"
+
+func TestAccountGraphRoutes(t *testing.T) {
+	g := loadTestGraph(t, "../config/accounts.conf")
+
+	tests := []struct {
+		account, subject string
+		// want maps each account the message reaches to its subject there.
+		want map[string]string
+	}{
+		{"A", "puba.x", map[string]string{"A": "puba.x", "C": "from_a.puba.x"}},
+		{"A", "b.data", map[string]string{"A": "b.data", "B": "b.data"}},
+		{"C", "Q", map[string]string{"C": "Q", "A": "pubq.C"}},
+		{"B", "q.b", map[string]string{"B": "q.b", "A": "q.b"}},
+		// C may not import b.>, and b.data is not a service import in C.
+		{"C", "b.data", map[string]string{"C": "b.data"}},
+		{"B", "b.data", map[string]string{"B": "b.data"}},
+	}
+	for _, tt := range tests {
+		routes := g.Routes(tt.account, tt.subject)
+		if routes[0].Account != tt.account || routes[0].Path() != "local" {
+			t.Errorf("Routes(%s, %s)[0] = %+v, want the local route", tt.account, tt.subject, routes[0])
+		}
+		got := map[string]string{}
+		for _, r := range routes {
+			got[r.Account] = r.Subject
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Routes(%s, %s) reach %v, want %v", tt.account, tt.subject, got, tt.want)
+			continue
+		}
+		for account, subject := range tt.want {
+			if got[account] != subject {
+				t.Errorf("Routes(%s, %s) reach %v, want %v", tt.account, tt.subject, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestAccountRoutePath(t *testing.T) {
+	g := loadTestGraph(t, "../config/accounts.conf")
+
+	tests := []struct {
+		account, subject, to string
+		want                 string
+	}{
+		{"A", "puba.x", "C", "stream A->C puba.> prefix from_a"},
+		{"C", "Q", "A", "service C->A pubq.C to Q"},
+	}
+	for _, tt := range tests {
+		var path string
+		for _, r := range g.Routes(tt.account, tt.subject) {
+			if r.Account == tt.to {
+				path = r.Path()
+			}
+		}
+		if path != tt.want {
+			t.Errorf("path from %s %s to %s = %q, want %q", tt.account, tt.subject, tt.to, path, tt.want)
+		}
+	}
+}
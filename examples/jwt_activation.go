@@ -0,0 +1,342 @@
+package examples
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+// validateAccount checks an account's claims before they are re-signed,
+// so a bad export or import is reported instead of being ignored by the
+// server.
+func validateAccount(acc *JWTAccount) error {
+	vr := jwt.CreateValidationResults()
+	acc.Claims.Validate(vr)
+	if errs := vr.Errors(); len(errs) > 0 {
+		return fmt.Errorf("invalid claims for account %s: %w", acc.Name, errs[0])
+	}
+	return nil
+}
+
+// AddExport adds export to the account, replacing any export of the same
+// type and subject, and re-signs it. Set TokenReq to make importers
+// present an activation token issued by the account.
+func (op *JWTOperator) AddExport(account string, export *jwt.Export) error {
+	acc, err := op.Account(account)
+	if err != nil {
+		return err
+	}
+
+	exports := jwt.Exports{}
+	for _, e := range acc.Claims.Exports {
+		if e.Type != export.Type || e.Subject != export.Subject {
+			exports = append(exports, e)
+		}
+	}
+	previous := acc.Claims.Exports
+	acc.Claims.Exports = append(exports, export)
+	if err := validateAccount(acc); err != nil {
+		acc.Claims.Exports = previous
+		return err
+	}
+	return op.SignAccount(acc)
+}
+
+// findExport returns the account's export of kind that contains subject.
+func (acc *JWTAccount) findExport(subject string, kind jwt.ExportType) *jwt.Export {
+	for _, e := range acc.Claims.Exports {
+		if e.Type == kind && jwt.Subject(subject).IsContainedIn(e.Subject) {
+			return e
+		}
+	}
+	return nil
+}
+
+// IssueActivation signs an activation token with the exporter's key,
+// allowing importer to import subject. A zero expiresIn never expires.
+func (op *JWTOperator) IssueActivation(exporter, subject string, kind jwt.ExportType, importer string, expiresIn time.Duration) (string, error) {
+	exp, err := op.Account(exporter)
+	if err != nil {
+		return "", err
+	}
+	imp, err := op.Account(importer)
+	if err != nil {
+		return "", err
+	}
+	if exp.findExport(subject, kind) == nil {
+		return "", fmt.Errorf("account %s has no %s export containing %s", exporter, kind, subject)
+	}
+
+	kp, err := nkeys.FromSeed([]byte(exp.Seed))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse seed for account %s: %w", exporter, err)
+	}
+
+	ac := jwt.NewActivationClaims(imp.PublicKey)
+	ac.Name = fmt.Sprintf("%s %s for %s", kind, subject, importer)
+	ac.ImportSubject = jwt.Subject(subject)
+	ac.ImportType = kind
+	if expiresIn > 0 {
+		ac.Expires = time.Now().Add(expiresIn).Unix()
+	}
+
+	token, err := ac.Encode(kp)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign activation for %s: %w", importer, err)
+	}
+	return token, nil
+}
+
+// ImportSpec describes an import from another account's export. Token is
+// the activation for a token-required export. LocalSubject is where a
+// stream appears, or where requests to a service are published, in the
+// importing account; empty means Subject.
+type ImportSpec struct {
+	From         string
+	Subject      string
+	Type         jwt.ExportType
+	LocalSubject string
+	Token        string
+}
+
+// AddImport adds an import to the account, replacing any import of the
+// same type and subject from the same account, and re-signs it. An
+// activation token must be issued to this account for this subject.
+func (op *JWTOperator) AddImport(account string, spec ImportSpec) error {
+	acc, err := op.Account(account)
+	if err != nil {
+		return err
+	}
+	from, err := op.Account(spec.From)
+	if err != nil {
+		return err
+	}
+
+	imp := &jwt.Import{
+		Name:         fmt.Sprintf("%s %s from %s", spec.Type, spec.Subject, spec.From),
+		Subject:      jwt.Subject(spec.Subject),
+		Account:      from.PublicKey,
+		Token:        spec.Token,
+		LocalSubject: jwt.RenamingSubject(spec.LocalSubject),
+		Type:         spec.Type,
+	}
+	if spec.Token != "" {
+		act, err := jwt.DecodeActivationClaims(spec.Token)
+		if err != nil {
+			return fmt.Errorf("failed to decode activation token: %w", err)
+		}
+		if act.Expires != 0 && act.Expires <= time.Now().Unix() {
+			return fmt.Errorf("activation for %s expired at %s", spec.Subject,
+				time.Unix(act.Expires, 0).Format(time.RFC3339))
+		}
+	}
+
+	imports := jwt.Imports{}
+	for _, i := range acc.Claims.Imports {
+		if i.Type != imp.Type || i.Subject != imp.Subject || i.Account != imp.Account {
+			imports = append(imports, i)
+		}
+	}
+	previous := acc.Claims.Imports
+	acc.Claims.Imports = append(imports, imp)
+	if err := validateAccount(acc); err != nil {
+		acc.Claims.Imports = previous
+		return err
+	}
+	return op.SignAccount(acc)
+}
+
+// ParseExportType parses "stream" or "service".
+func ParseExportType(s string) (jwt.ExportType, error) {
+	switch s {
+	case "stream":
+		return jwt.Stream, nil
+	case "service":
+		return jwt.Service, nil
+	}
+	return jwt.Unknown, fmt.Errorf("unknown export type %q, want stream or service", s)
+}
+
+// expectStream reports whether a message pub publishes on subject reaches sub.
+func expectStream(pub *nats.Conn, sub *nats.Subscription, subject, label string, want bool) {
+	pub.Publish(subject, []byte("private data"))
+	pub.Flush()
+	_, err := sub.NextMsg(500 * time.Millisecond)
+	switch {
+	case err == nil && want:
+		fmt.Printf("✓ %s received %s\n", label, subject)
+	case err != nil && !want:
+		fmt.Printf("✗ %s did not receive %s\n", label, subject)
+	case err == nil:
+		fmt.Printf("❌ %s received %s\n", label, subject)
+	default:
+		fmt.Printf("❌ %s did not receive %s: %v\n", label, subject, err)
+	}
+}
+
+// DemoActivationTokens demonstrates private exports in operator mode:
+// token-required exports and activation tokens embedded in the importer's
+// account JWT, mirroring the private part of DemoAccountExports
+func DemoActivationTokens() {
+	fmt.Println("\n=== Activation Tokens for Private Exports Demo ===")
+
+	resolverDir, err := os.MkdirTemp("", "nats-activation-resolver-")
+	if err != nil {
+		log.Printf("Failed to create resolver dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(resolverDir)
+
+	op, err := NewJWTOperator("activation")
+	if err != nil {
+		log.Printf("Operator setup failed: %v", err)
+		return
+	}
+	for _, name := range []string{"A", "B", "C"} {
+		if _, err := op.AddAccount(name); err != nil {
+			log.Printf("Account setup failed: %v", err)
+			return
+		}
+	}
+
+	fmt.Println("\n1. Account A exports b.> and q.b, both token-required:")
+	for _, export := range []*jwt.Export{
+		{Name: "private stream", Subject: "b.>", Type: jwt.Stream, TokenReq: true},
+		{Name: "private service", Subject: "q.b", Type: jwt.Service, TokenReq: true},
+	} {
+		if err := op.AddExport("A", export); err != nil {
+			log.Printf("Export failed: %v", err)
+			return
+		}
+		fmt.Printf("✓ %s export %s (token required)\n", export.Type, export.Subject)
+	}
+
+	fmt.Println("\n2. A issues activations to B, embedded in B's imports:")
+	for _, imp := range []ImportSpec{
+		{From: "A", Subject: "b.>", Type: jwt.Stream},
+		{From: "A", Subject: "q.b", Type: jwt.Service},
+	} {
+		imp.Token, err = op.IssueActivation("A", imp.Subject, imp.Type, "B", time.Hour)
+		if err != nil {
+			log.Printf("Activation failed: %v", err)
+			return
+		}
+		if err := op.AddImport("B", imp); err != nil {
+			log.Printf("Import failed: %v", err)
+			return
+		}
+		fmt.Printf("✓ B imports %s %s with an activation expiring in 1h\n", imp.Type, imp.Subject)
+	}
+
+	// C imports the stream without an activation; the JWT is valid, but the
+	// server ignores the import.
+	if err := op.AddImport("C", ImportSpec{From: "A", Subject: "b.>", Type: jwt.Stream}); err != nil {
+		log.Printf("Import failed: %v", err)
+		return
+	}
+	fmt.Println("  C imports stream b.> without an activation")
+
+	opts, err := op.ServerOptions(resolverDir)
+	if err != nil {
+		log.Printf("Server options failed: %v", err)
+		return
+	}
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Embedded server failed: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+
+	connect := func(account string) *nats.Conn {
+		user, err := op.IssueUser(account, UserSpec{Name: "user_" + account})
+		if err != nil {
+			log.Printf("Issue user in %s failed: %v", account, err)
+			return nil
+		}
+		nc, err := nats.Connect(url, user.ConnectOption())
+		if err != nil {
+			log.Printf("Account %s connection failed: %v", account, err)
+			return nil
+		}
+		return nc
+	}
+	connA, connB, connC := connect("A"), connect("B"), connect("C")
+	for _, nc := range []*nats.Conn{connA, connB, connC} {
+		if nc == nil {
+			return
+		}
+		defer nc.Close()
+	}
+
+	fmt.Println("\n3. Private stream and service with activations:")
+	subB, _ := connB.SubscribeSync("b.data")
+	subC, _ := connC.SubscribeSync("b.data")
+	connB.Flush()
+	connC.Flush()
+	expectStream(connA, subB, "b.data", "Account B", true)
+	expectStream(connA, subC, "b.data", "Account C (no activation)", false)
+
+	connA.Subscribe("q.b", func(msg *nats.Msg) {
+		msg.Respond([]byte("Private response for B"))
+	})
+	connA.Flush()
+	if resp, err := connB.Request("q.b", []byte("Request from B"), 2*time.Second); err == nil {
+		fmt.Printf("✓ Account B received response: %s\n", resp.Data)
+	} else {
+		fmt.Printf("❌ Account B request failed: %v\n", err)
+	}
+
+	fmt.Println("\n4. Activations are bound to one importer:")
+	tokenB, err := op.IssueActivation("A", "b.>", jwt.Stream, "B", time.Hour)
+	if err != nil {
+		log.Printf("Activation failed: %v", err)
+		return
+	}
+	if err := op.AddImport("C", ImportSpec{From: "A", Subject: "b.>", Type: jwt.Stream, Token: tokenB}); err != nil {
+		fmt.Printf("✗ C embedding B's activation: %v\n", err)
+	} else {
+		fmt.Println("❌ C embedded B's activation")
+	}
+	if _, err := op.IssueActivation("A", "a.>", jwt.Stream, "C", time.Hour); err != nil {
+		fmt.Printf("✗ Activation for an unexported subject: %v\n", err)
+	}
+
+	fmt.Println("\n5. A short-lived activation for C:")
+	tokenC, err := op.IssueActivation("A", "b.>", jwt.Stream, "C", 3*time.Second)
+	if err != nil {
+		log.Printf("Activation failed: %v", err)
+		return
+	}
+	if err := op.AddImport("C", ImportSpec{From: "A", Subject: "b.>", Type: jwt.Stream, Token: tokenC}); err != nil {
+		log.Printf("Import failed: %v", err)
+		return
+	}
+	sys, err := op.ConnectSystem(url)
+	if err != nil {
+		log.Printf("System connection failed: %v", err)
+		return
+	}
+	defer sys.Close()
+	if err := op.PushAccount(sys, "C"); err != nil {
+		log.Printf("Push failed: %v", err)
+		return
+	}
+	fmt.Println("✓ Pushed C's account JWT with an activation expiring in 3s")
+	expectStream(connA, subC, "b.data", "Account C", true)
+
+	time.Sleep(3500 * time.Millisecond)
+	fmt.Println("  ...activation expired")
+	expectStream(connA, subC, "b.data", "Account C", false)
+	if err := op.AddImport("C", ImportSpec{From: "A", Subject: "b.>", Type: jwt.Stream, Token: tokenC}); err != nil {
+		fmt.Printf("✗ Re-embedding the expired activation: %v\n", err)
+	}
+
+	fmt.Println("\n=== Activation Tokens for Private Exports Demo Complete ===")
+}
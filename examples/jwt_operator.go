@@ -0,0 +1,466 @@
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+// SystemAccountName is the name given to the system account of every
+// operator created by NewJWTOperator.
+const SystemAccountName = "SYS"
+
+// JWTAccount is an account signed by a JWTOperator. Seed is the account's
+// signing key; JWT is the most recently signed version of Claims.
+type JWTAccount struct {
+	Name      string             `json:"name"`
+	Seed      string             `json:"seed"`
+	PublicKey string             `json:"public_key"`
+	JWT       string             `json:"jwt"`
+	Claims    *jwt.AccountClaims `json:"-"`
+}
+
+// JWTOperator holds everything needed to run NATS in operator (JWT) mode:
+// the operator identity, its system account and the accounts it signs.
+type JWTOperator struct {
+	Name      string                 `json:"name"`
+	Seed      string                 `json:"seed"`
+	PublicKey string                 `json:"public_key"`
+	JWT       string                 `json:"jwt"`
+	Accounts  map[string]*JWTAccount `json:"accounts"`
+}
+
+// UserSpec describes a user to issue under an account.
+type UserSpec struct {
+	Name        string
+	Permissions jwt.Permissions
+	// ExpiresIn sets the JWT exp claim relative to now; zero means no expiry.
+	ExpiresIn time.Duration
+	// Limits caps the user's subscriptions, data and payload size; nil
+	// leaves them unlimited.
+	Limits *jwt.NatsLimits
+	// Src restricts connections to these CIDR blocks; empty allows any.
+	Src []string
+	// Times restricts connections to these daily windows (HH:MM:SS) in
+	// Locale, or in the server's local time when Locale is empty.
+	Times  []jwt.TimeRange
+	Locale string
+}
+
+// IssuedUser is a freshly issued user JWT together with the seed that
+// proves possession of its key.
+type IssuedUser struct {
+	Name      string
+	Account   string
+	PublicKey string
+	Seed      string
+	JWT       string
+	Expires   time.Time
+}
+
+// NewJWTOperator creates an operator with a system account.
+func NewJWTOperator(name string) (*JWTOperator, error) {
+	kp, err := nkeys.CreateOperator()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create operator nkey: %w", err)
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator seed: %w", err)
+	}
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator public key: %w", err)
+	}
+
+	op := &JWTOperator{
+		Name:      name,
+		Seed:      string(seed),
+		PublicKey: publicKey,
+		Accounts:  map[string]*JWTAccount{},
+	}
+
+	sys, err := op.AddAccount(SystemAccountName)
+	if err != nil {
+		return nil, err
+	}
+
+	oc := jwt.NewOperatorClaims(publicKey)
+	oc.Name = name
+	oc.SystemAccount = sys.PublicKey
+	op.JWT, err = oc.Encode(kp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign operator jwt: %w", err)
+	}
+
+	return op, nil
+}
+
+// LoadJWTOperator reads an operator store written by Save.
+func LoadJWTOperator(filename string) (*JWTOperator, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operator store: %w", err)
+	}
+
+	var op JWTOperator
+	if err := json.Unmarshal(data, &op); err != nil {
+		return nil, fmt.Errorf("failed to parse operator store: %w", err)
+	}
+
+	for name, acc := range op.Accounts {
+		acc.Claims, err = jwt.DecodeAccountClaims(acc.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode jwt for account %s: %w", name, err)
+		}
+	}
+
+	return &op, nil
+}
+
+// Save writes the operator, including all seeds, to filename.
+func (op *JWTOperator) Save(filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(op, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode operator store: %w", err)
+	}
+
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		return fmt.Errorf("failed to write operator store: %w", err)
+	}
+
+	return nil
+}
+
+// AddAccount creates and signs a new account.
+func (op *JWTOperator) AddAccount(name string) (*JWTAccount, error) {
+	if _, ok := op.Accounts[name]; ok {
+		return nil, fmt.Errorf("account %s already exists", name)
+	}
+
+	kp, err := nkeys.CreateAccount()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account nkey for %s: %w", name, err)
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seed for %s: %w", name, err)
+	}
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key for %s: %w", name, err)
+	}
+
+	ac := jwt.NewAccountClaims(publicKey)
+	ac.Name = name
+
+	acc := &JWTAccount{
+		Name:      name,
+		Seed:      string(seed),
+		PublicKey: publicKey,
+		Claims:    ac,
+	}
+	if err := op.SignAccount(acc); err != nil {
+		return nil, err
+	}
+
+	op.Accounts[name] = acc
+	return acc, nil
+}
+
+// Account returns the named account.
+func (op *JWTOperator) Account(name string) (*JWTAccount, error) {
+	acc, ok := op.Accounts[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown account %s", name)
+	}
+	return acc, nil
+}
+
+// AccountNames returns the names of all accounts, sorted.
+func (op *JWTOperator) AccountNames() []string {
+	names := make([]string, 0, len(op.Accounts))
+	for name := range op.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SignAccount re-signs acc.Claims with the operator key, refreshing acc.JWT.
+// Call it after every change to the account claims.
+func (op *JWTOperator) SignAccount(acc *JWTAccount) error {
+	kp, err := nkeys.FromSeed([]byte(op.Seed))
+	if err != nil {
+		return fmt.Errorf("failed to parse operator seed: %w", err)
+	}
+
+	token, err := acc.Claims.Encode(kp)
+	if err != nil {
+		return fmt.Errorf("failed to sign account %s: %w", acc.Name, err)
+	}
+
+	acc.JWT = token
+	return nil
+}
+
+// IssueUser creates a new user key and signs a user JWT for it with the
+// account's key.
+func (op *JWTOperator) IssueUser(account string, spec UserSpec) (*IssuedUser, error) {
+	acc, err := op.Account(account)
+	if err != nil {
+		return nil, err
+	}
+
+	accountKP, err := nkeys.FromSeed([]byte(acc.Seed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse seed for account %s: %w", account, err)
+	}
+
+	kp, err := nkeys.CreateUser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user nkey for %s: %w", spec.Name, err)
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seed for %s: %w", spec.Name, err)
+	}
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key for %s: %w", spec.Name, err)
+	}
+
+	uc := jwt.NewUserClaims(publicKey)
+	uc.Name = spec.Name
+	uc.Permissions = spec.Permissions
+	if spec.Limits != nil {
+		uc.NatsLimits = *spec.Limits
+	}
+	uc.Src.Add(spec.Src...)
+	uc.Times = spec.Times
+	uc.Locale = spec.Locale
+
+	var expires time.Time
+	if spec.ExpiresIn > 0 {
+		expires = time.Now().Add(spec.ExpiresIn).Truncate(time.Second)
+		uc.Expires = expires.Unix()
+	}
+
+	vr := jwt.CreateValidationResults()
+	uc.Validate(vr)
+	if errs := vr.Errors(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid claims for user %s: %w", spec.Name, errs[0])
+	}
+
+	token, err := uc.Encode(accountKP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign user %s: %w", spec.Name, err)
+	}
+
+	return &IssuedUser{
+		Name:      spec.Name,
+		Account:   account,
+		PublicKey: publicKey,
+		Seed:      string(seed),
+		JWT:       token,
+		Expires:   expires,
+	}, nil
+}
+
+// RevokeUser adds the user's public key to the account's revocation list,
+// invalidating every JWT issued to that key up to now, and re-signs the
+// account. The change takes effect once the account JWT is pushed.
+func (op *JWTOperator) RevokeUser(account, userPublicKey string) error {
+	if !nkeys.IsValidPublicUserKey(userPublicKey) {
+		return fmt.Errorf("%q is not a user public key", userPublicKey)
+	}
+
+	acc, err := op.Account(account)
+	if err != nil {
+		return err
+	}
+
+	acc.Claims.Revoke(userPublicKey)
+	return op.SignAccount(acc)
+}
+
+// PushAccount sends the account's current JWT to a running server through
+// the system account, the same way `nsc push` does.
+func (op *JWTOperator) PushAccount(nc *nats.Conn, account string) error {
+	acc, err := op.Account(account)
+	if err != nil {
+		return err
+	}
+
+	msg, err := nc.Request("$SYS.REQ.CLAIMS.UPDATE", []byte(acc.JWT), 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to push account %s: %w", account, err)
+	}
+
+	var resp struct {
+		Data *struct {
+			Message string `json:"message"`
+		} `json:"data"`
+		Error *struct {
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(msg.Data, &resp); err != nil {
+		return fmt.Errorf("failed to parse push response: %w", err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("server rejected account %s: %s", account, resp.Error.Description)
+	}
+
+	return nil
+}
+
+// ConnectSystem connects to url as a freshly issued system account user.
+func (op *JWTOperator) ConnectSystem(url string) (*nats.Conn, error) {
+	user, err := op.IssueUser(SystemAccountName, UserSpec{Name: "sys", ExpiresIn: 5 * time.Minute})
+	if err != nil {
+		return nil, err
+	}
+
+	nc, err := nats.Connect(url, user.ConnectOption(), nats.Name("system"))
+	if err != nil {
+		return nil, fmt.Errorf("system account connection failed: %w", err)
+	}
+	return nc, nil
+}
+
+// ServerOptions returns options for an embedded server that trusts this
+// operator and keeps account JWTs in a full resolver rooted at resolverDir.
+func (op *JWTOperator) ServerOptions(resolverDir string) (*server.Options, error) {
+	oc, err := jwt.DecodeOperatorClaims(op.JWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode operator jwt: %w", err)
+	}
+
+	resolver, err := server.NewDirAccResolver(resolverDir, 0, time.Minute, server.NoDelete)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account resolver: %w", err)
+	}
+	for _, acc := range op.Accounts {
+		if err := resolver.SaveAcc(acc.PublicKey, acc.JWT); err != nil {
+			return nil, fmt.Errorf("failed to store account %s: %w", acc.Name, err)
+		}
+	}
+
+	return &server.Options{
+		TrustedOperators: []*jwt.OperatorClaims{oc},
+		SystemAccount:    oc.SystemAccount,
+		AccountResolver:  resolver,
+	}, nil
+}
+
+// WriteServerConfig writes a nats-server configuration that trusts this
+// operator and preloads all account JWTs into a full resolver.
+func (op *JWTOperator) WriteServerConfig(filename string, port int, resolverDir string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	sys, err := op.Account(SystemAccountName)
+	if err != nil {
+		return err
+	}
+
+	names := op.AccountNames()
+
+	fmt.Fprintln(f, "# Generated JWT (operator mode) Configuration")
+	fmt.Fprintln(f, "# Auto-generated - account JWTs are updated with `nats-demo jwt push`")
+	fmt.Fprintln(f, "")
+	fmt.Fprintf(f, "port: %d\n", port)
+	fmt.Fprintln(f, "")
+	fmt.Fprintf(f, "operator: %s\n", op.JWT)
+	fmt.Fprintf(f, "system_account: %s\n", sys.PublicKey)
+	fmt.Fprintln(f, "")
+	fmt.Fprintln(f, "resolver {")
+	fmt.Fprintln(f, "  type: full")
+	fmt.Fprintf(f, "  dir: %q\n", resolverDir)
+	fmt.Fprintln(f, "  allow_delete: false")
+	fmt.Fprintln(f, "}")
+	fmt.Fprintln(f, "")
+	fmt.Fprintln(f, "resolver_preload {")
+	for _, name := range names {
+		acc := op.Accounts[name]
+		fmt.Fprintf(f, "  # Account %s\n", name)
+		fmt.Fprintf(f, "  %s: %s\n", acc.PublicKey, acc.JWT)
+	}
+	fmt.Fprintln(f, "}")
+
+	return nil
+}
+
+// ConnectOption authenticates a connection with the user's JWT and seed.
+func (u *IssuedUser) ConnectOption() nats.Option {
+	return nats.UserJWTAndSeed(u.JWT, u.Seed)
+}
+
+// Creds renders the user as a .creds file.
+func (u *IssuedUser) Creds() ([]byte, error) {
+	creds, err := jwt.FormatUserConfig(u.JWT, []byte(u.Seed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format creds for %s: %w", u.Name, err)
+	}
+	return creds, nil
+}
+
+// PermissionsFor builds JWT permissions from publish and subscribe subject lists.
+func PermissionsFor(publish, subscribe []string) jwt.Permissions {
+	var perms jwt.Permissions
+	perms.Pub.Allow.Add(publish...)
+	perms.Sub.Allow.Add(subscribe...)
+	return perms
+}
+
+// ParseTimeRanges parses comma-separated HH:MM:SS-HH:MM:SS windows, as
+// used by UserSpec.Times.
+func ParseTimeRanges(s string) ([]jwt.TimeRange, error) {
+	var ranges []jwt.TimeRange
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		start, end, ok := strings.Cut(item, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid time window %q, want HH:MM:SS-HH:MM:SS", item)
+		}
+		for _, t := range []string{start, end} {
+			if _, err := time.Parse("15:04:05", t); err != nil {
+				return nil, fmt.Errorf("invalid time %q in window %q", t, item)
+			}
+		}
+		ranges = append(ranges, jwt.TimeRange{Start: start, End: end})
+	}
+	return ranges, nil
+}
+
+// describeExpiry formats an expiry time for demo output.
+func describeExpiry(expires time.Time) string {
+	if expires.IsZero() {
+		return "never"
+	}
+	return fmt.Sprintf("%s (in %s)", expires.Format("15:04:05"), time.Until(expires).Round(time.Second))
+}
@@ -0,0 +1,182 @@
+package examples
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats.go"
+)
+
+// loopbackAvailable reports whether ip can be used as a local address.
+// Linux routes all of 127.0.0.0/8 to lo; macOS needs an alias per address.
+func loopbackAvailable(ip string) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort(ip, "0"))
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
+
+// connectFrom connects as user with ip as the source address.
+func connectFrom(url, ip string, user *IssuedUser) (*nats.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   2 * time.Second,
+		LocalAddr: &net.TCPAddr{IP: net.ParseIP(ip)},
+	}
+	return nats.Connect(url, user.ConnectOption(), nats.SetCustomDialer(dialer), nats.NoReconnect())
+}
+
+// reportAttempt prints whether a connection attempt went the expected way.
+func reportAttempt(label string, allow bool, err error) {
+	switch {
+	case err == nil && allow:
+		fmt.Printf("✓ %s accepted\n", label)
+	case err != nil && !allow:
+		fmt.Printf("✗ %s rejected: %v\n", label, err)
+	case err == nil:
+		fmt.Printf("❌ %s was accepted\n", label)
+	default:
+		fmt.Printf("❌ %s was rejected: %v\n", label, err)
+	}
+}
+
+// DemoUserRestrictions demonstrates user JWTs that only allow connections
+// from some source addresses, or during some time windows
+func DemoUserRestrictions() {
+	fmt.Println("\n=== Source-IP & Time-Window Restrictions Demo ===")
+
+	resolverDir, err := os.MkdirTemp("", "nats-restrictions-resolver-")
+	if err != nil {
+		log.Printf("Failed to create resolver dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(resolverDir)
+
+	op, err := NewJWTOperator("restrictions")
+	if err != nil {
+		log.Printf("Operator setup failed: %v", err)
+		return
+	}
+	if _, err := op.AddAccount("APP"); err != nil {
+		log.Printf("Account setup failed: %v", err)
+		return
+	}
+	opts, err := op.ServerOptions(resolverDir)
+	if err != nil {
+		log.Printf("Server options failed: %v", err)
+		return
+	}
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Embedded server failed: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+
+	fmt.Println("\n1. Source-IP restrictions (src):")
+	aliases := []string{"127.0.0.2", "127.0.0.3", "127.0.0.5"}
+	for _, ip := range aliases {
+		if !loopbackAvailable(ip) {
+			fmt.Printf("  Loopback address %s is not available; on macOS add it with\n", ip)
+			fmt.Printf("  `sudo ifconfig lo0 alias %s`. Skipping source-IP checks.\n", ip)
+			aliases = nil
+			break
+		}
+	}
+
+	if aliases != nil {
+		office, err := op.IssueUser("APP", UserSpec{Name: "office", Src: []string{"127.0.0.2/32"}})
+		if err != nil {
+			log.Printf("Issue office failed: %v", err)
+			return
+		}
+		lab, err := op.IssueUser("APP", UserSpec{Name: "lab", Src: []string{"127.0.0.0/30"}})
+		if err != nil {
+			log.Printf("Issue lab failed: %v", err)
+			return
+		}
+		fmt.Println("  office: src 127.0.0.2/32")
+		fmt.Println("  lab:    src 127.0.0.0/30 (127.0.0.0 - 127.0.0.3)")
+
+		attempts := []struct {
+			user  *IssuedUser
+			ip    string
+			allow bool
+		}{
+			{office, "127.0.0.2", true},
+			{office, "127.0.0.3", false},
+			{office, "127.0.0.1", false},
+			{lab, "127.0.0.3", true},
+			{lab, "127.0.0.5", false},
+		}
+		for _, a := range attempts {
+			nc, err := connectFrom(url, a.ip, a.user)
+			reportAttempt(fmt.Sprintf("%s from %s", a.user.Name, a.ip), a.allow, err)
+			if nc != nil {
+				nc.Close()
+			}
+		}
+	}
+
+	fmt.Println("\n2. Time-window restrictions (times):")
+	// Windows are whole seconds; start on a second boundary a little ahead.
+	now := time.Now().UTC()
+	opens := now.Truncate(time.Second).Add(3 * time.Second)
+	closes := opens.Add(4 * time.Second)
+	window := jwt.TimeRange{Start: opens.Format("15:04:05"), End: closes.Format("15:04:05")}
+	past := jwt.TimeRange{
+		Start: now.Add(-time.Hour).Format("15:04:05"),
+		End:   now.Add(-time.Hour + time.Minute).Format("15:04:05"),
+	}
+
+	shift, err := op.IssueUser("APP", UserSpec{Name: "shift", Times: []jwt.TimeRange{window}, Locale: "UTC"})
+	if err != nil {
+		log.Printf("Issue shift failed: %v", err)
+		return
+	}
+	lapsed, err := op.IssueUser("APP", UserSpec{Name: "lapsed", Times: []jwt.TimeRange{past}, Locale: "UTC"})
+	if err != nil {
+		log.Printf("Issue lapsed failed: %v", err)
+		return
+	}
+	fmt.Printf("  shift:  %s-%s UTC (opens in ~3s, closes 4s later)\n", window.Start, window.End)
+	fmt.Printf("  lapsed: %s-%s UTC (closed an hour ago)\n", past.Start, past.End)
+
+	expect := func(user *IssuedUser, allow bool) *nats.Conn {
+		at := time.Now().UTC().Format("15:04:05.0")
+		nc, err := nats.Connect(url, user.ConnectOption(), nats.NoReconnect())
+		reportAttempt(fmt.Sprintf("[%s] %s", at, user.Name), allow, err)
+		if nc != nil && !allow {
+			nc.Close()
+			return nil
+		}
+		return nc
+	}
+
+	expect(lapsed, false)
+	expect(shift, false)
+
+	time.Sleep(time.Until(opens.Add(500 * time.Millisecond)))
+	nc := expect(shift, true)
+	if nc != nil {
+		errs := asyncErrors(nc)
+		if err := waitError(errs, time.Until(closes.Add(3*time.Second))); err != nil {
+			fmt.Printf("✗ [%s] shift's connection closed when the window ended: %v\n",
+				time.Now().UTC().Format("15:04:05.0"), err)
+		} else {
+			fmt.Println("❌ shift's connection outlived the window")
+		}
+		nc.Close()
+	}
+
+	time.Sleep(time.Until(closes.Add(500 * time.Millisecond)))
+	expect(shift, false)
+
+	fmt.Println("\n=== Source-IP & Time-Window Restrictions Demo Complete ===")
+}
@@ -0,0 +1,187 @@
+package examples
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// watchedConn is a connection whose disconnects and async errors are
+// recorded so the demo can report why the server dropped it.
+type watchedConn struct {
+	*nats.Conn
+	events chan string
+}
+
+func connectWatched(url string, user *IssuedUser, extra ...nats.Option) (*watchedConn, error) {
+	events := make(chan string, 16)
+	nc, err := nats.Connect(url, append([]nats.Option{
+		user.ConnectOption(),
+		nats.Name(user.Name),
+		nats.MaxReconnects(2),
+		nats.ReconnectWait(250*time.Millisecond),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			events <- fmt.Sprintf("server error: %v", err)
+		}),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				events <- fmt.Sprintf("disconnected: %v", err)
+			}
+		}),
+		nats.ClosedHandler(func(nc *nats.Conn) {
+			events <- fmt.Sprintf("closed: %v", nc.LastError())
+		}),
+	}, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &watchedConn{Conn: nc, events: events}, nil
+}
+
+// waitClosed reports connection events until the connection is closed or
+// the timeout passes.
+func (w *watchedConn) waitClosed(timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case ev := <-w.events:
+			fmt.Printf("    • %s\n", ev)
+			if strings.HasPrefix(ev, "closed") {
+				return true
+			}
+		case <-deadline:
+			return w.IsClosed()
+		}
+	}
+}
+
+// DemoUserRevocation demonstrates cutting off users in JWT mode, either by
+// revoking their key in the account JWT or by letting their JWT expire
+func DemoUserRevocation() {
+	fmt.Println("\n=== User Revocation & Credential Expiry Demo ===")
+
+	resolverDir, err := os.MkdirTemp("", "nats-jwt-resolver-")
+	if err != nil {
+		log.Printf("Failed to create resolver dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(resolverDir)
+
+	fmt.Println("\n1. Creating operator, system account and account APP...")
+	op, err := NewJWTOperator("demo")
+	if err != nil {
+		log.Printf("Operator setup failed: %v", err)
+		return
+	}
+	if _, err := op.AddAccount("APP"); err != nil {
+		log.Printf("Account setup failed: %v", err)
+		return
+	}
+
+	opts, err := op.ServerOptions(resolverDir)
+	if err != nil {
+		log.Printf("Server options failed: %v", err)
+		return
+	}
+	srv, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Embedded server failed: %v", err)
+		return
+	}
+	defer srv.Shutdown()
+	url := srv.ClientURL()
+	fmt.Printf("  ✓ Embedded server running in operator mode at %s\n", url)
+
+	sysConn, err := op.ConnectSystem(url)
+	if err != nil {
+		log.Printf("System connection failed: %v", err)
+		return
+	}
+	defer sysConn.Close()
+
+	fmt.Println("\n2. Issuing users:")
+	perms := PermissionsFor([]string{"app.>"}, []string{"app.>", "_INBOX.>"})
+	alice, err := op.IssueUser("APP", UserSpec{Name: "alice", Permissions: perms})
+	if err != nil {
+		log.Printf("Issue alice failed: %v", err)
+		return
+	}
+	bob, err := op.IssueUser("APP", UserSpec{Name: "bob", Permissions: perms, ExpiresIn: 3 * time.Second})
+	if err != nil {
+		log.Printf("Issue bob failed: %v", err)
+		return
+	}
+	for _, u := range []*IssuedUser{alice, bob} {
+		fmt.Printf("  %s: %s (expires: %s)\n", u.Name, u.PublicKey, describeExpiry(u.Expires))
+	}
+
+	aliceConn, err := connectWatched(url, alice)
+	if err != nil {
+		log.Printf("Alice connection failed: %v", err)
+		return
+	}
+	defer aliceConn.Close()
+	bobConn, err := connectWatched(url, bob)
+	if err != nil {
+		log.Printf("Bob connection failed: %v", err)
+		return
+	}
+	defer bobConn.Close()
+	fmt.Println("  ✓ alice and bob connected")
+
+	fmt.Println("\n3. Revoking alice and pushing the updated account JWT:")
+	if err := op.RevokeUser("APP", alice.PublicKey); err != nil {
+		log.Printf("Revoke failed: %v", err)
+		return
+	}
+	if err := op.PushAccount(sysConn, "APP"); err != nil {
+		log.Printf("Push failed: %v", err)
+		return
+	}
+	fmt.Println("  ✓ Account APP now lists alice in its revocations")
+	if aliceConn.waitClosed(3 * time.Second) {
+		fmt.Println("  ✓ alice's live connection was dropped by the server")
+	} else {
+		fmt.Println("  ✗ alice is still connected")
+	}
+
+	if nc, err := nats.Connect(url, alice.ConnectOption()); err != nil {
+		fmt.Printf("  ✓ alice correctly rejected on reconnect: %v\n", err)
+	} else {
+		fmt.Println("  ✗ alice was able to reconnect")
+		nc.Close()
+	}
+
+	fmt.Println("\n4. Waiting for bob's credentials to expire:")
+	if bobConn.waitClosed(time.Until(bob.Expires) + 3*time.Second) {
+		fmt.Println("  ✓ bob's live connection was dropped when the JWT expired")
+	} else {
+		fmt.Println("  ✗ bob is still connected")
+	}
+
+	if nc, err := nats.Connect(url, bob.ConnectOption()); err != nil {
+		fmt.Printf("  ✓ bob correctly rejected on reconnect: %v\n", err)
+	} else {
+		fmt.Println("  ✗ bob was able to reconnect")
+		nc.Close()
+	}
+
+	fmt.Println("\n5. A newly issued user is unaffected:")
+	carol, err := op.IssueUser("APP", UserSpec{Name: "carol", Permissions: perms})
+	if err != nil {
+		log.Printf("Issue carol failed: %v", err)
+		return
+	}
+	if nc, err := nats.Connect(url, carol.ConnectOption()); err != nil {
+		fmt.Printf("  ✗ carol connection failed: %v\n", err)
+	} else {
+		fmt.Println("  ✓ carol connected")
+		nc.Close()
+	}
+
+	fmt.Println("\n=== User Revocation & Credential Expiry Demo Complete ===")
+}
@@ -0,0 +1,272 @@
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultInventoryFile is where the key generation commands record the keys
+// they create.
+const DefaultInventoryFile = "generated/keys.json"
+
+// KeyStatus is the lifecycle state of an inventoried key.
+type KeyStatus string
+
+const (
+	KeyActive  KeyStatus = "active"
+	KeyRotated KeyStatus = "rotated"
+	KeyRevoked KeyStatus = "revoked"
+)
+
+// InventoryEntry is the metadata kept for one key. Seeds are never stored
+// in the inventory.
+type InventoryEntry struct {
+	Role            string     `json:"role"`
+	PublicKey       string     `json:"public_key"`
+	Type            string     `json:"type"`
+	CreatedAt       time.Time  `json:"created_at"`
+	CreatedBy       string     `json:"created_by"`
+	ReferencedBy    []string   `json:"referenced_by,omitempty"`
+	Status          KeyStatus  `json:"status"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	ReplacedBy      string     `json:"replaced_by,omitempty"`
+}
+
+// KeyInventory is a JSON manifest of every key the tooling has generated.
+type KeyInventory struct {
+	Keys []*InventoryEntry `json:"keys"`
+	path string
+}
+
+// AuditFinding is one problem reported by KeyInventory.Audit.
+type AuditFinding struct {
+	PublicKey string
+	Role      string
+	Problem   string
+}
+
+// LoadKeyInventory reads the inventory at path. A missing file yields an
+// empty inventory that Save will create.
+func LoadKeyInventory(path string) (*KeyInventory, error) {
+	inv := &KeyInventory{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return inv, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key inventory: %w", err)
+	}
+
+	if err := json.Unmarshal(data, inv); err != nil {
+		return nil, fmt.Errorf("failed to parse key inventory: %w", err)
+	}
+	return inv, nil
+}
+
+// Save writes the inventory back to the file it was loaded from.
+func (inv *KeyInventory) Save() error {
+	if err := os.MkdirAll(filepath.Dir(inv.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode key inventory: %w", err)
+	}
+
+	if err := os.WriteFile(inv.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write key inventory: %w", err)
+	}
+	return nil
+}
+
+// Find returns the entry for a public key, or nil.
+func (inv *KeyInventory) Find(publicKey string) *InventoryEntry {
+	for _, e := range inv.Keys {
+		if e.PublicKey == publicKey {
+			return e
+		}
+	}
+	return nil
+}
+
+// Record adds a newly generated key as active. Recording a key twice keeps
+// the original entry.
+func (inv *KeyInventory) Record(role, publicKey, keyType string) *InventoryEntry {
+	if e := inv.Find(publicKey); e != nil {
+		return e
+	}
+
+	e := &InventoryEntry{
+		Role:      role,
+		PublicKey: publicKey,
+		Type:      keyType,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		CreatedBy: keyCreator(),
+		Status:    KeyActive,
+	}
+	inv.Keys = append(inv.Keys, e)
+	return e
+}
+
+// AddReference notes that a generated config uses the key.
+func (inv *KeyInventory) AddReference(publicKey, configFile string) error {
+	e := inv.Find(publicKey)
+	if e == nil {
+		return fmt.Errorf("key %s is not in the inventory", publicKey)
+	}
+
+	for _, ref := range e.ReferencedBy {
+		if ref == configFile {
+			return nil
+		}
+	}
+	e.ReferencedBy = append(e.ReferencedBy, configFile)
+	sort.Strings(e.ReferencedBy)
+	return nil
+}
+
+// SetStatus marks a key as rotated or revoked. replacedBy names the key that
+// took over, if any.
+func (inv *KeyInventory) SetStatus(publicKey string, status KeyStatus, replacedBy string) error {
+	switch status {
+	case KeyActive, KeyRotated, KeyRevoked:
+	default:
+		return fmt.Errorf("unknown key status %q", status)
+	}
+
+	e := inv.Find(publicKey)
+	if e == nil {
+		return fmt.Errorf("key %s is not in the inventory", publicKey)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	e.Status = status
+	e.StatusChangedAt = &now
+	e.ReplacedBy = replacedBy
+	return nil
+}
+
+// Audit reports active keys older than maxAge, keys used in configs that
+// the inventory does not know about, and retired keys still in use.
+// configs are server configs or, for .json files, operator stores.
+func (inv *KeyInventory) Audit(maxAge time.Duration, configs []string) ([]AuditFinding, error) {
+	var findings []AuditFinding
+
+	if maxAge > 0 {
+		for _, e := range inv.Keys {
+			if age := time.Since(e.CreatedAt); e.Status == KeyActive && age > maxAge {
+				findings = append(findings, AuditFinding{
+					PublicKey: e.PublicKey,
+					Role:      e.Role,
+					Problem:   fmt.Sprintf("active for %s, older than the %s policy", formatAge(age), formatAge(maxAge)),
+				})
+			}
+		}
+	}
+
+	for _, config := range configs {
+		found, err := CollectKeyReferences(config)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range found {
+			e := inv.Find(key.Key)
+			switch {
+			case e == nil:
+				findings = append(findings, AuditFinding{
+					PublicKey: key.Key,
+					Problem:   fmt.Sprintf("used at %s in %s but missing from the inventory", key.Path, config),
+				})
+			case e.Status != KeyActive:
+				findings = append(findings, AuditFinding{
+					PublicKey: key.Key,
+					Role:      e.Role,
+					Problem:   fmt.Sprintf("%s but still used at %s in %s", e.Status, key.Path, config),
+				})
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// RecordGeneratedKeys adds keys from GenerateNKeysForRoles to the inventory
+// at path and notes the configs that reference them.
+func RecordGeneratedKeys(path string, keys []GeneratedNKey, configs ...string) error {
+	inv, err := LoadKeyInventory(path)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		inv.Record(key.Role, key.PublicKey, key.Type.String())
+		for _, config := range configs {
+			if err := inv.AddReference(key.PublicKey, config); err != nil {
+				return err
+			}
+		}
+	}
+
+	return inv.Save()
+}
+
+// ParseKeyAge accepts Go durations ("720h") and whole days ("90d").
+func ParseKeyAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func formatAge(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	return d.Round(time.Second).String()
+}
+
+// keyCreator identifies who generated a key as user@host.
+func keyCreator() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
+
+// PrintKeyInventory prints the inventory as a table.
+func PrintKeyInventory(inv *KeyInventory) {
+	if len(inv.Keys) == 0 {
+		fmt.Println("No keys in the inventory")
+		return
+	}
+
+	fmt.Printf("%-12s %-9s %-8s %-20s %-8s %s\n", "ROLE", "TYPE", "STATUS", "CREATED", "AGE", "PUBLIC KEY")
+	for _, e := range inv.Keys {
+		fmt.Printf("%-12s %-9s %-8s %-20s %-8s %s\n",
+			e.Role, e.Type, e.Status, e.CreatedAt.Format("2006-01-02 15:04:05"),
+			formatAge(time.Since(e.CreatedAt)), e.PublicKey)
+		if len(e.ReferencedBy) > 0 {
+			fmt.Printf("%-12s used by: %s\n", "", strings.Join(e.ReferencedBy, ", "))
+		}
+		if e.ReplacedBy != "" {
+			fmt.Printf("%-12s replaced by: %s\n", "", e.ReplacedBy)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package examples
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// LimitCheck is the outcome of pushing one limit until it trips.
+type LimitCheck struct {
+	// Scope is what the limit applies to, e.g. "account A" or "user alice".
+	Scope string
+	// Limit is the configured field, e.g. "max_connections".
+	Limit      string
+	Configured int64
+	// TrippedAt is the connection or subscription count, or the payload
+	// size, that was refused; zero if the limit never tripped.
+	TrippedAt int64
+	Err       error
+}
+
+// LimitFromError names the limit a server or client error reports. The
+// server tells clients their effective max_payload after they connect, so
+// oversized messages are usually refused by the client before sending.
+func LimitFromError(err error) string {
+	if err == nil {
+		return ""
+	}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, nats.ErrMaxPayload):
+		return "max_payload (refused by client)"
+	case strings.Contains(msg, "maximum account active connections"):
+		return "max_connections (account)"
+	case strings.Contains(msg, "maximum connections"):
+		return "max_connections (server)"
+	case strings.Contains(msg, "maximum subscriptions"):
+		return "max_subscriptions"
+	case strings.Contains(msg, "maximum payload"):
+		return "max_payload (refused by server)"
+	}
+	return "unknown"
+}
+
+// probeConnections opens connections until the server refuses one or
+// attempts is reached, then closes them. It returns how many were accepted.
+func probeConnections(url string, attempts int, opts ...nats.Option) (int, error) {
+	var conns []*nats.Conn
+	defer func() {
+		for _, nc := range conns {
+			nc.Close()
+		}
+	}()
+
+	for i := 0; i < attempts; i++ {
+		nc, err := nats.Connect(url, opts...)
+		if err != nil {
+			return len(conns), err
+		}
+		conns = append(conns, nc)
+	}
+	return len(conns), nil
+}
+
+// probeSubscriptions subscribes on nc until the server closes it or
+// attempts is reached. It returns how many subscriptions succeeded.
+func probeSubscriptions(nc *nats.Conn, attempts int) (int, error) {
+	for i := 0; i < attempts; i++ {
+		if _, err := nc.SubscribeSync(fmt.Sprintf("limits.sub.%d", i)); err != nil {
+			return i, err
+		}
+		if err := nc.FlushTimeout(time.Second); err != nil {
+			if last := nc.LastError(); last != nil {
+				err = last
+			}
+			return i, err
+		}
+	}
+	return attempts, nil
+}
+
+// probePayload publishes a message of size bytes and reports whether the
+// client or the server refused it.
+func probePayload(nc *nats.Conn, size int) error {
+	if err := nc.Publish("limits.payload", make([]byte, size)); err != nil {
+		return err
+	}
+	if err := nc.FlushTimeout(time.Second); err != nil {
+		if last := nc.LastError(); last != nil {
+			return last
+		}
+		return err
+	}
+	return nil
+}
+
+// limitConn connects for a single probe. Errors the server reports after
+// connecting are read from LastError, so they are not printed here.
+func limitConn(url string, opts ...nats.Option) (*nats.Conn, error) {
+	opts = append(opts, nats.NoReconnect(),
+		nats.ErrorHandler(func(*nats.Conn, *nats.Subscription, error) {}))
+	return nats.Connect(url, opts...)
+}
+
+// checkConnections, checkSubscriptions and checkPayload run a probe and
+// record it as a LimitCheck.
+func checkConnections(scope string, limit int64, url string, opts ...nats.Option) LimitCheck {
+	opened, err := probeConnections(url, int(limit)+2, opts...)
+	c := LimitCheck{Scope: scope, Limit: "max_connections", Configured: limit, Err: err}
+	if err != nil {
+		c.TrippedAt = int64(opened + 1)
+	}
+	return c
+}
+
+func checkSubscriptions(scope string, limit int64, url string, opts ...nats.Option) LimitCheck {
+	c := LimitCheck{Scope: scope, Limit: "max_subscriptions", Configured: limit}
+	nc, err := limitConn(url, opts...)
+	if err != nil {
+		c.Err = err
+		return c
+	}
+	defer nc.Close()
+
+	subs, err := probeSubscriptions(nc, int(limit)+2)
+	c.Err = err
+	if err != nil {
+		c.TrippedAt = int64(subs + 1)
+	}
+	return c
+}
+
+func checkPayload(scope string, limit int64, size int, url string, opts ...nats.Option) LimitCheck {
+	c := LimitCheck{Scope: scope, Limit: "max_payload", Configured: limit}
+	nc, err := limitConn(url, opts...)
+	if err != nil {
+		c.Err = err
+		return c
+	}
+	defer nc.Close()
+
+	// The flush after the first message also picks up the payload limit
+	// the server advertises for this user.
+	if err := probePayload(nc, int(limit)/2); err != nil {
+		c.Err = fmt.Errorf("payload below the limit refused: %w", err)
+		return c
+	}
+	if int64(nc.MaxPayload()) != limit {
+		fmt.Printf("  Server advertises max_payload %d to %s\n", nc.MaxPayload(), scope)
+	}
+	c.Err = probePayload(nc, size)
+	if c.Err != nil {
+		c.TrippedAt = int64(size)
+	}
+	return c
+}
+
+// PrintLimitReport prints which limits tripped, and the limit each error
+// names.
+func PrintLimitReport(checks []LimitCheck) {
+	fmt.Printf("\n  %-14s %-18s %-10s %-10s %s\n", "SCOPE", "LIMIT", "VALUE", "TRIPPED", "REPORTED AS")
+	for _, c := range checks {
+		tripped := "no"
+		if c.TrippedAt > 0 {
+			tripped = fmt.Sprintf("at %d", c.TrippedAt)
+		}
+		fmt.Printf("  %-14s %-18s %-10d %-10s %s\n", c.Scope, c.Limit, c.Configured, tripped, LimitFromError(c.Err))
+	}
+}
+
+// printLimitCheck reports a single check as it completes.
+func printLimitCheck(c LimitCheck, unit string) {
+	if c.TrippedAt == 0 {
+		fmt.Printf("❌ %s %s (%d) did not trip: %v\n", c.Scope, c.Limit, c.Configured, c.Err)
+		return
+	}
+	fmt.Printf("✗ %s refused at %s %d (limit %d): %v\n", c.Scope, unit, c.TrippedAt, c.Configured, c.Err)
+}
+
+// DemoLimits demonstrates connection, subscription and payload limits set
+// per account in a server config, and per user and account in JWTs
+func DemoLimits() {
+	fmt.Println("\n=== Connection, Subscription & Payload Limits Demo ===")
+
+	var report []LimitCheck
+
+	fmt.Println("\n1. Account limits from config/limits.conf:")
+	opts, err := server.ProcessConfigFile("config/limits.conf")
+	if err != nil {
+		log.Printf("Failed to load config/limits.conf: %v", err)
+		return
+	}
+	opts.Port = server.RANDOM_PORT
+
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+	fmt.Printf("  Server: max_connections %d, max_subscriptions %d per connection, max_payload %d\n",
+		opts.MaxConn, opts.MaxSubs, opts.MaxPayload)
+	fmt.Println("  Account A: max_connections 3, max_subscriptions 10, max_payload 1024")
+
+	userA := userPassword("user_a", "pass_a")
+	userB := userPassword("user_b", "pass_b")
+
+	c := checkConnections("account A", 3, url, userA)
+	printLimitCheck(c, "connection")
+	report = append(report, c)
+
+	c = checkSubscriptions("account A", 10, url, userA)
+	printLimitCheck(c, "subscription")
+	report = append(report, c)
+
+	c = checkPayload("account A", 1024, 2048, url, userA)
+	printLimitCheck(c, "payload bytes")
+	report = append(report, c)
+
+	fmt.Println("\n2. Server-wide limits, seen from account B:")
+	c = checkSubscriptions("server", int64(opts.MaxSubs), url, userB)
+	printLimitCheck(c, "subscription")
+	report = append(report, c)
+
+	c = checkPayload("server", int64(opts.MaxPayload), int(opts.MaxPayload)+1, url, userB)
+	printLimitCheck(c, "payload bytes")
+	report = append(report, c)
+
+	fmt.Println("\n3. User and account limits in JWTs (operator mode):")
+	jwtChecks, err := demoJWTLimits()
+	if err != nil {
+		log.Printf("JWT limits demo failed: %v", err)
+	}
+	report = append(report, jwtChecks...)
+
+	fmt.Println("\n4. Report:")
+	PrintLimitReport(report)
+
+	fmt.Println("\n=== Connection, Subscription & Payload Limits Demo Complete ===")
+}
+
+// demoJWTLimits sets a connection limit on an account JWT and
+// subscription and payload limits on a user JWT, and trips each of them.
+func demoJWTLimits() ([]LimitCheck, error) {
+	resolverDir, err := os.MkdirTemp("", "nats-limits-resolver-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resolver dir: %w", err)
+	}
+	defer os.RemoveAll(resolverDir)
+
+	op, err := NewJWTOperator("limits")
+	if err != nil {
+		return nil, err
+	}
+	acc, err := op.AddAccount("TENANT")
+	if err != nil {
+		return nil, err
+	}
+	acc.Claims.Limits.Conn = 2
+	if err := op.SignAccount(acc); err != nil {
+		return nil, err
+	}
+
+	opts, err := op.ServerOptions(resolverDir)
+	if err != nil {
+		return nil, err
+	}
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+
+	limits := jwt.NatsLimits{Subs: 3, Data: jwt.NoLimit, Payload: 256}
+	alice, err := op.IssueUser("TENANT", UserSpec{
+		Name:        "alice",
+		Permissions: PermissionsFor([]string{"limits.>"}, []string{"limits.>"}),
+		Limits:      &limits,
+	})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("  Account TENANT JWT: conn 2")
+	fmt.Printf("  User alice JWT: subs %d, payload %d\n", limits.Subs, limits.Payload)
+
+	var checks []LimitCheck
+	c := checkConnections("account TENANT", acc.Claims.Limits.Conn, url, alice.ConnectOption())
+	printLimitCheck(c, "connection")
+	checks = append(checks, c)
+
+	c = checkSubscriptions("user alice", limits.Subs, url, alice.ConnectOption())
+	printLimitCheck(c, "subscription")
+	checks = append(checks, c)
+
+	c = checkPayload("user alice", limits.Payload, 512, url, alice.ConnectOption())
+	printLimitCheck(c, "payload bytes")
+	checks = append(checks, c)
+
+	return checks, nil
+}
@@ -0,0 +1,211 @@
+package examples
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// MQTT 3.1.1 packet types used by MQTTClient.
+const (
+	mqttConnect    = 0x10
+	mqttConnAck    = 0x20
+	mqttPublish    = 0x30
+	mqttSubscribe  = 0x82
+	mqttSubAck     = 0x90
+	mqttDisconnect = 0xe0
+)
+
+// MQTTConnectError is a CONNACK with a non-zero return code.
+type MQTTConnectError struct {
+	Code byte
+}
+
+func (e *MQTTConnectError) Error() string {
+	reasons := map[byte]string{
+		1: "unacceptable protocol version",
+		2: "identifier rejected",
+		3: "server unavailable",
+		4: "bad user name or password",
+		5: "not authorized",
+	}
+	if reason, ok := reasons[e.Code]; ok {
+		return fmt.Sprintf("mqtt: connection refused: %s (code %d)", reason, e.Code)
+	}
+	return fmt.Sprintf("mqtt: connection refused (code %d)", e.Code)
+}
+
+// MQTTClient is a minimal MQTT 3.1.1 client, enough to stand in for an IoT
+// device: connect with a user and password, subscribe and publish at QoS 0.
+type MQTTClient struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	nextID uint16
+}
+
+// DialMQTT connects to addr and sends CONNECT with a clean session.
+func DialMQTT(addr, clientID, user, password string) (*MQTTClient, error) {
+	conn, err := net.DialTimeout("tcp", addr, 2*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial mqtt: %w", err)
+	}
+	c := &MQTTClient{conn: conn, r: bufio.NewReader(conn)}
+
+	var body bytes.Buffer
+	writeMQTTString(&body, "MQTT")
+	body.WriteByte(4)                  // protocol level 3.1.1
+	body.WriteByte(0x80 | 0x40 | 0x02) // user name, password, clean session
+	binary.Write(&body, binary.BigEndian, uint16(60))
+	writeMQTTString(&body, clientID)
+	writeMQTTString(&body, user)
+	writeMQTTString(&body, password)
+
+	if err := c.write(mqttConnect, body.Bytes()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	typ, payload, err := c.read(5 * time.Second)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if typ != mqttConnAck || len(payload) != 2 {
+		conn.Close()
+		return nil, fmt.Errorf("mqtt: expected CONNACK, got packet type %#x", typ)
+	}
+	if payload[1] != 0 {
+		conn.Close()
+		return nil, &MQTTConnectError{Code: payload[1]}
+	}
+	return c, nil
+}
+
+// Subscribe subscribes to topic at QoS 0 and waits for the SUBACK.
+func (c *MQTTClient) Subscribe(topic string) error {
+	c.nextID++
+	var body bytes.Buffer
+	binary.Write(&body, binary.BigEndian, c.nextID)
+	writeMQTTString(&body, topic)
+	body.WriteByte(0)
+
+	if err := c.write(mqttSubscribe, body.Bytes()); err != nil {
+		return err
+	}
+	for {
+		typ, payload, err := c.read(5 * time.Second)
+		if err != nil {
+			return fmt.Errorf("failed to read SUBACK: %w", err)
+		}
+		if typ != mqttSubAck {
+			continue
+		}
+		if len(payload) < 3 || payload[2] == 0x80 {
+			return fmt.Errorf("mqtt: subscription to %s refused", topic)
+		}
+		return nil
+	}
+}
+
+// Publish sends payload to topic at QoS 0.
+func (c *MQTTClient) Publish(topic string, payload []byte) error {
+	var body bytes.Buffer
+	writeMQTTString(&body, topic)
+	body.Write(payload)
+	return c.write(mqttPublish, body.Bytes())
+}
+
+// ReadMessage waits for the next PUBLISH and returns its topic and payload.
+func (c *MQTTClient) ReadMessage(timeout time.Duration) (string, []byte, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		typ, payload, err := c.read(time.Until(deadline))
+		if err != nil {
+			return "", nil, err
+		}
+		if typ&0xf0 != mqttPublish {
+			continue
+		}
+		if len(payload) < 2 {
+			return "", nil, fmt.Errorf("mqtt: short PUBLISH")
+		}
+		n := int(binary.BigEndian.Uint16(payload))
+		if len(payload) < 2+n {
+			return "", nil, fmt.Errorf("mqtt: short PUBLISH topic")
+		}
+		topic, rest := string(payload[2:2+n]), payload[2+n:]
+		if qos := (typ >> 1) & 0x03; qos > 0 && len(rest) >= 2 {
+			rest = rest[2:] // packet identifier
+		}
+		return topic, rest, nil
+	}
+}
+
+// Close sends DISCONNECT and closes the connection.
+func (c *MQTTClient) Close() error {
+	c.write(mqttDisconnect, nil)
+	return c.conn.Close()
+}
+
+func (c *MQTTClient) write(typ byte, body []byte) error {
+	var pkt bytes.Buffer
+	pkt.WriteByte(typ)
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		pkt.WriteByte(b)
+		if n == 0 {
+			break
+		}
+	}
+	pkt.Write(body)
+
+	c.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	if _, err := c.conn.Write(pkt.Bytes()); err != nil {
+		return fmt.Errorf("failed to write mqtt packet: %w", err)
+	}
+	return nil
+}
+
+func (c *MQTTClient) read(timeout time.Duration) (byte, []byte, error) {
+	c.conn.SetReadDeadline(time.Now().Add(timeout))
+	typ, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, nil, fmt.Errorf("mqtt: malformed remaining length")
+		}
+		multiplier *= 128
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+	return typ, payload, nil
+}
+
+func writeMQTTString(b *bytes.Buffer, s string) {
+	binary.Write(b, binary.BigEndian, uint16(len(s)))
+	b.WriteString(s)
+}
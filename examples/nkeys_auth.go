@@ -28,29 +28,41 @@ var predefinedUsers = []NKeyUser{
 	},
 	{
 		Name:           "Client",
-		Seed:           "SUAM42UG6PV55WVNPAHKF65J4SJQNWQVNWQP7H2VQWPQVH2SJQNWQVH2SABC",
-		PublicKey:      "UAH42UG6PV55WVNPAHKF65J4SJQNWQVNWQP7H2VQWPQVH2SJQNWQVH2S",
+		Seed:           "SUAKKODQBTPAQTP2EPNNEPMUJMKLJY7WXBMJFWO5YSJ63JGPBWHDTQQONE",
+		PublicKey:      "UBZBKRBRYLYABZEZZPBF4IR3NBMJIYGUH7GGXMVFFKUTOUIWUEHJJRIM",
 		CanPublishTo:   []string{"req.a", "req.b"},
 		CanSubscribeTo: []string{"_INBOX.>"},
 	},
 	{
 		Name:           "Service",
-		Seed:           "SUBFJ4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF5XYZ",
-		PublicKey:      "UBFJ4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF5",
+		Seed:           "SUAPMCCFW7WZFU5JOU74CJJQQRZVTRWXXHKBKXCYAQGHBKOPDM5QBLEAVY",
+		PublicKey:      "UDNGH4SEREQA7JEXKKUKVHOM5XCZYQM6WG2J73PHUAJJGC4ZTNRXS5HS",
 		CanPublishTo:   []string{"_INBOX.>"},
 		CanSubscribeTo: []string{"req.a", "req.b"},
 	},
 	{
 		Name:           "Other",
-		Seed:           "SUCGH5RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF6DEF",
-		PublicKey:      "UCGH5RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF6",
+		Seed:           "SUAKMFH7EONC335QRHNNALEI7DSGZWVWNXA5UDSBCJGJ74JZXWVSRWOCAQ",
+		PublicKey:      "UDDUI2T5IL5DRUUHIOYCYV55YM4WIBKHFBWLRZFRZFUP2OJQ6M3P5APN",
 		CanPublishTo:   []string{"SANDBOX.*"},
 		CanSubscribeTo: []string{"PUBLIC.>", "_INBOX.>"},
 	},
 }
 
+// nkeyOption authenticates with an nkey seed, which may be given directly
+// or as a secret reference such as "env:ADMIN_SEED".
 func nkeyOption(seed string) nats.Option {
+	return resolvedNKeyOption(DefaultSecrets, seed)
+}
+
+// resolvedNKeyOption is nkeyOption with references resolved by secrets.
+func resolvedNKeyOption(secrets *SecretResolver, seed string) nats.Option {
 	return func(o *nats.Options) error {
+		seed, err := secrets.Resolve(seed)
+		if err != nil {
+			return err
+		}
+
 		kp, err := nkeys.FromSeed([]byte(seed))
 		if err != nil {
 			return fmt.Errorf("failed to parse seed: %w", err)
@@ -76,6 +88,10 @@ func nkeyOption(seed string) nats.Option {
 }
 
 func connectWithNKey(user NKeyUser) (*nats.Conn, error) {
+	if problems := CheckNKeyUser(user); len(problems) > 0 {
+		return nil, fmt.Errorf("nkey for %s is unusable: %s", user.Name, strings.Join(problems, "; "))
+	}
+
 	nc, err := nats.Connect(
 		"nats://localhost:4227",
 		nkeyOption(user.Seed),
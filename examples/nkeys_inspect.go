@@ -0,0 +1,161 @@
+package examples
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/nats-io/nkeys"
+)
+
+// NKeyInspection is the decoded form of a seed or public key.
+type NKeyInspection struct {
+	Input     string
+	IsSeed    bool
+	Type      nkeys.PrefixByte
+	PublicKey string
+	Err       error
+}
+
+// NKeyUserCheck lists what is wrong with one predefined NKey user.
+type NKeyUserCheck struct {
+	Name     string
+	Problems []string
+}
+
+// nkeyTokenPattern matches anything that looks like a seed or public key,
+// whatever its length, so that truncated or padded keys are reported
+// rather than skipped.
+var nkeyTokenPattern = regexp.MustCompile(`\b(S[A-Z2-7]{20,}|[OAUNCX][A-Z2-7]{20,})\b`)
+
+// Encoded lengths of a seed and a public key.
+const (
+	nkeySeedLength   = 58
+	nkeyPublicLength = 56
+)
+
+// InspectNKey decodes a seed or public key, verifying its checksum. For a
+// seed, PublicKey is the key derived from it.
+func InspectNKey(key string) NKeyInspection {
+	in := NKeyInspection{Input: key, Type: nkeys.PrefixByteUnknown}
+
+	if len(key) > 0 && key[0] == 'S' {
+		in.IsSeed = true
+		if len(key) != nkeySeedLength {
+			in.Err = fmt.Errorf("invalid seed: %d characters, want %d", len(key), nkeySeedLength)
+			return in
+		}
+		prefix, _, err := nkeys.DecodeSeed([]byte(key))
+		if err != nil {
+			in.Err = fmt.Errorf("invalid seed: %w", err)
+			return in
+		}
+		in.Type = prefix
+
+		kp, err := nkeys.FromSeed([]byte(key))
+		if err != nil {
+			in.Err = fmt.Errorf("failed to parse seed: %w", err)
+			return in
+		}
+		in.PublicKey, in.Err = kp.PublicKey()
+		return in
+	}
+
+	if len(key) != nkeyPublicLength {
+		in.Err = fmt.Errorf("invalid public key: %d characters, want %d", len(key), nkeyPublicLength)
+		return in
+	}
+	if _, err := nkeys.FromPublicKey(key); err != nil {
+		in.Err = fmt.Errorf("invalid public key: %w", err)
+		return in
+	}
+	in.Type = nkeys.Prefix(key)
+	in.PublicKey = key
+	return in
+}
+
+// InspectNKeyFile inspects every key-shaped token in a file, such as a
+// generated nkeys.txt, a .creds file or a server config.
+func InspectNKeyFile(filename string) ([]NKeyInspection, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	var results []NKeyInspection
+	for _, token := range nkeyTokenPattern.FindAllString(string(data), -1) {
+		results = append(results, InspectNKey(token))
+	}
+	return results, nil
+}
+
+// CheckNKeyUser confirms that a user's seed (or the secret it references) is
+// a valid user seed and derives the public key it is configured with.
+func CheckNKeyUser(user NKeyUser) []string {
+	var problems []string
+
+	resolved, err := DefaultSecrets.Resolve(user.Seed)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	if err := ValidateSeed(resolved, nkeys.PrefixByteUser); err != nil {
+		problems = append(problems, err.Error())
+	} else if seed := InspectNKey(resolved); seed.Err != nil {
+		problems = append(problems, seed.Err.Error())
+	} else if seed.PublicKey != user.PublicKey {
+		problems = append(problems, fmt.Sprintf("seed derives %s, not the configured %s", seed.PublicKey, user.PublicKey))
+	}
+
+	if err := ValidatePublicKey(user.PublicKey, nkeys.PrefixByteUser); err != nil {
+		problems = append(problems, fmt.Sprintf("public key: %v", err))
+	}
+
+	return problems
+}
+
+// CheckPredefinedUsers runs CheckNKeyUser over the users DemoNKeysAuth
+// connects with, and checks that each public key is present in configFile.
+func CheckPredefinedUsers(configFile string) ([]NKeyUserCheck, error) {
+	found, err := CollectConfigNKeys(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	configured := map[string]bool{}
+	for _, key := range found {
+		configured[key.Key] = true
+	}
+
+	checks := make([]NKeyUserCheck, 0, len(predefinedUsers))
+	for _, user := range predefinedUsers {
+		problems := CheckNKeyUser(user)
+		if !configured[user.PublicKey] {
+			problems = append(problems, fmt.Sprintf("public key is not configured in %s", configFile))
+		}
+		checks = append(checks, NKeyUserCheck{Name: user.Name, Problems: problems})
+	}
+
+	return checks, nil
+}
+
+// PrintNKeyInspection prints one inspection result.
+func PrintNKeyInspection(in NKeyInspection) {
+	kind, shown := "public key", in.Input
+	if in.IsSeed {
+		kind = "seed"
+		if len(shown) > 20 {
+			shown = shown[:20] + "..."
+		}
+	}
+
+	if in.Err != nil {
+		fmt.Printf("✗ %s (%s): %v\n", shown, kind, in.Err)
+		return
+	}
+
+	fmt.Printf("✓ %s (%s)\n", shown, kind)
+	fmt.Printf("  Type:        %v\n", in.Type)
+	fmt.Printf("  Public Key:  %s\n", in.PublicKey)
+	fmt.Println("  Checksum:    ok")
+}
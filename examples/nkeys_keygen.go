@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats-server/v2/conf"
 	"github.com/nats-io/nkeys"
 )
 
 type GeneratedNKey struct {
 	Role      string
+	Type      nkeys.PrefixByte
 	Seed      string
 	PublicKey string
 }
@@ -36,6 +41,7 @@ func GenerateNKeysForRoles() ([]GeneratedNKey, error) {
 
 		keys = append(keys, GeneratedNKey{
 			Role:      role,
+			Type:      nkeys.PrefixByteUser,
 			Seed:      string(seed),
 			PublicKey: publicKey,
 		})
@@ -72,76 +78,243 @@ func SaveNKeysToFile(keys []GeneratedNKey, filename string) error {
 }
 
 func GenerateServerConfig(keys []GeneratedNKey, filename string) error {
-	dir := filepath.Dir(filename)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	for _, key := range keys {
+		if err := ValidatePublicKey(key.PublicKey, nkeys.PrefixByteUser); err != nil {
+			return fmt.Errorf("%s cannot be used in a user nkey field: %w", key.Role, err)
+		}
 	}
 
-	f, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	cfg := &ServerConfig{
+		Header:             []string{"Generated NKeys Authentication Configuration", "Auto-generated - modify as needed"},
+		Port:               4227,
+		DefaultPermissions: demoDefaultPermissions(),
+		Roles:              demoRoles(),
 	}
-	defer f.Close()
-
-	fmt.Fprintln(f, "# Generated NKeys Authentication Configuration")
-	fmt.Fprintln(f, "# Auto-generated - modify as needed")
-	fmt.Fprintln(f, "")
-	fmt.Fprintln(f, "port: 4227")
-	fmt.Fprintln(f, "")
-	fmt.Fprintln(f, "authorization {")
-	fmt.Fprintln(f, "  default_permissions = {")
-	fmt.Fprintln(f, "    publish = \"SANDBOX.*\"")
-	fmt.Fprintln(f, "    subscribe = [\"PUBLIC.>\", \"_INBOX.>\"]")
-	fmt.Fprintln(f, "  }")
-	fmt.Fprintln(f, "")
-	fmt.Fprintln(f, "  ADMIN = {")
-	fmt.Fprintln(f, "    publish = \">\"")
-	fmt.Fprintln(f, "    subscribe = \">\"")
-	fmt.Fprintln(f, "  }")
-	fmt.Fprintln(f, "")
-	fmt.Fprintln(f, "  REQUESTOR = {")
-	fmt.Fprintln(f, "    publish = [\"req.a\", \"req.b\"]")
-	fmt.Fprintln(f, "    subscribe = \"_INBOX.>\"")
-	fmt.Fprintln(f, "  }")
-	fmt.Fprintln(f, "")
-	fmt.Fprintln(f, "  RESPONDER = {")
-	fmt.Fprintln(f, "    subscribe = [\"req.a\", \"req.b\"]")
-	fmt.Fprintln(f, "    publish = \"_INBOX.>\"")
-	fmt.Fprintln(f, "  }")
-	fmt.Fprintln(f, "")
-	fmt.Fprintln(f, "  users = [")
 
-	for i, key := range keys {
-		var permission string
+	for _, key := range keys {
+		var role string
 		switch key.Role {
 		case "Admin":
-			permission = "$ADMIN"
+			role = "ADMIN"
 		case "Client":
-			permission = "$REQUESTOR"
+			role = "REQUESTOR"
 		case "Service":
-			permission = "$RESPONDER"
-		default:
-			permission = ""
+			role = "RESPONDER"
+		}
+		cfg.Users = append(cfg.Users, ConfigUser{Comment: key.Role + " User", NKey: key.PublicKey, Role: role})
+	}
+
+	return cfg.WriteFile(filename)
+}
+
+// ConfigNKey is an nkey found in a server config, with the key type its
+// position in the config requires.
+type ConfigNKey struct {
+	Path     string
+	Key      string
+	Expected nkeys.PrefixByte
+}
+
+// CollectConfigNKeys finds the nkeys in a server config: user nkeys under
+// authorization and account users, operator keys under trusted_keys, and
+// in operator mode the operator JWT, the system account, and the account
+// JWTs of resolver_preload and of a resolver directory.
+func CollectConfigNKeys(filename string) ([]ConfigNKey, error) {
+	cfg, err := conf.ParseFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	var found []ConfigNKey
+	collectUsers := func(where string, users interface{}) {
+		list, _ := users.([]interface{})
+		for i, u := range list {
+			user, _ := u.(map[string]interface{})
+			if nkey, ok := user["nkey"].(string); ok {
+				found = append(found, ConfigNKey{
+					Path:     fmt.Sprintf("%s.users[%d].nkey", where, i),
+					Key:      nkey,
+					Expected: nkeys.PrefixByteUser,
+				})
+			}
 		}
+	}
 
-		fmt.Fprintf(f, "    # %s User\n", key.Role)
-		if permission != "" {
-			fmt.Fprintf(f, "    {nkey: \"%s\", permissions: %s}", key.PublicKey, permission)
-		} else {
-			fmt.Fprintf(f, "    {nkey: \"%s\"}", key.PublicKey)
+	if auth, ok := cfg["authorization"].(map[string]interface{}); ok {
+		collectUsers("authorization", auth["users"])
+	}
+	if accounts, ok := cfg["accounts"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(accounts))
+		for name := range accounts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if acc, ok := accounts[name].(map[string]interface{}); ok {
+				collectUsers("accounts."+name, acc["users"])
+			}
 		}
+	}
+	switch trusted := cfg["trusted_keys"].(type) {
+	case string:
+		found = append(found, ConfigNKey{Path: "trusted_keys", Key: trusted, Expected: nkeys.PrefixByteOperator})
+	case []interface{}:
+		for i, k := range trusted {
+			key, _ := k.(string)
+			found = append(found, ConfigNKey{
+				Path:     fmt.Sprintf("trusted_keys[%d]", i),
+				Key:      key,
+				Expected: nkeys.PrefixByteOperator,
+			})
+		}
+	}
 
-		if i < len(keys)-1 {
-			fmt.Fprintln(f, ",")
-		} else {
-			fmt.Fprintln(f, "")
+	if operator, ok := cfg["operator"].(string); ok {
+		token, err := readConfigJWT(filename, operator)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := operatorJWTKeys("operator", token)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, keys...)
+	}
+	if sys, ok := cfg["system_account"].(string); ok && nkeys.IsValidPublicAccountKey(sys) {
+		found = append(found, ConfigNKey{Path: "system_account", Key: sys, Expected: nkeys.PrefixByteAccount})
+	}
+	if preload, ok := cfg["resolver_preload"].(map[string]interface{}); ok {
+		keys := make([]string, 0, len(preload))
+		for key := range preload {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			path := "resolver_preload." + key
+			token, _ := preload[key].(string)
+			accKeys, err := accountJWTKeys(path, token)
+			if err != nil {
+				return nil, err
+			}
+			if accKeys[0].Key != key {
+				found = append(found, ConfigNKey{Path: path, Key: key, Expected: nkeys.PrefixByteAccount})
+			}
+			found = append(found, accKeys...)
+		}
+	}
+	if resolver, ok := cfg["resolver"].(map[string]interface{}); ok {
+		if dir, ok := resolver["dir"].(string); ok {
+			files, _ := filepath.Glob(filepath.Join(dir, "*.jwt"))
+			for _, file := range files {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", file, err)
+				}
+				accKeys, err := accountJWTKeys("resolver "+file, string(data))
+				if err != nil {
+					return nil, err
+				}
+				found = append(found, accKeys...)
+			}
 		}
 	}
 
-	fmt.Fprintln(f, "  ]")
-	fmt.Fprintln(f, "}")
+	return found, nil
+}
 
-	return nil
+// readConfigJWT returns value if it is a JWT, or else the JWT in the file
+// it names, relative to the working directory or to config.
+func readConfigJWT(config, value string) (string, error) {
+	if strings.HasPrefix(value, "eyJ") {
+		return value, nil
+	}
+	data, err := os.ReadFile(value)
+	if err != nil {
+		if data, err = os.ReadFile(filepath.Join(filepath.Dir(config), value)); err != nil {
+			return "", fmt.Errorf("failed to read operator jwt %s: %w", value, err)
+		}
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// operatorJWTKeys returns the operator key and signing keys of an
+// operator JWT.
+func operatorJWTKeys(path, token string) ([]ConfigNKey, error) {
+	oc, err := jwt.DecodeOperatorClaims(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s jwt: %w", path, err)
+	}
+	found := []ConfigNKey{{Path: path, Key: oc.Subject, Expected: nkeys.PrefixByteOperator}}
+	for i, key := range oc.SigningKeys {
+		found = append(found, ConfigNKey{Path: fmt.Sprintf("%s.signing_keys[%d]", path, i), Key: key, Expected: nkeys.PrefixByteOperator})
+	}
+	return found, nil
+}
+
+// accountJWTKeys returns the account key, signing keys and issuer of an
+// account JWT.
+func accountJWTKeys(path, token string) ([]ConfigNKey, error) {
+	ac, err := jwt.DecodeAccountClaims(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s jwt: %w", path, err)
+	}
+	found := []ConfigNKey{
+		{Path: path, Key: ac.Subject, Expected: nkeys.PrefixByteAccount},
+		{Path: path + ".iss", Key: ac.Issuer, Expected: nkeys.PrefixByteOperator},
+	}
+	for _, key := range ac.SigningKeys.Keys() {
+		found = append(found, ConfigNKey{Path: path + ".signing_keys", Key: key, Expected: nkeys.PrefixByteAccount})
+	}
+	return found, nil
+}
+
+// CollectOperatorStoreKeys finds the keys in an operator store written by
+// `nats-demo jwt init`: the operator and every account, with the signing
+// keys and issuers of their JWTs.
+func CollectOperatorStoreKeys(filename string) ([]ConfigNKey, error) {
+	op, err := LoadJWTOperator(filename)
+	if err != nil {
+		return nil, err
+	}
+	found, err := operatorJWTKeys("operator", op.JWT)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range op.AccountNames() {
+		keys, err := accountJWTKeys("accounts."+name, op.Accounts[name].JWT)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, keys...)
+	}
+	return found, nil
+}
+
+// CollectKeyReferences finds the keys a file uses: an operator store for
+// .json files, otherwise a server config.
+func CollectKeyReferences(filename string) ([]ConfigNKey, error) {
+	if filepath.Ext(filename) == ".json" {
+		return CollectOperatorStoreKeys(filename)
+	}
+	return CollectConfigNKeys(filename)
+}
+
+// ValidateConfigNKeys checks every nkey in a server config against the key
+// type its position requires.
+func ValidateConfigNKeys(filename string) ([]error, error) {
+	found, err := CollectConfigNKeys(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []error
+	for _, key := range found {
+		if err := ValidatePublicKey(key.Key, key.Expected); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", key.Path, err))
+		}
+	}
+
+	return problems, nil
 }
 
 func DemoNKeyGenerationWithFiles() {
@@ -178,6 +351,24 @@ func DemoNKeyGenerationWithFiles() {
 	}
 	fmt.Println("✓ Server config generated successfully")
 
+	if err := RecordGeneratedKeys(DefaultInventoryFile, keys, configFile); err != nil {
+		fmt.Printf("Error updating key inventory: %v\n", err)
+		return
+	}
+	fmt.Printf("✓ Keys recorded in inventory: %s\n", DefaultInventoryFile)
+
+	problems, err := ValidateConfigNKeys(configFile)
+	if err != nil {
+		fmt.Printf("Error validating config: %v\n", err)
+		return
+	}
+	for _, problem := range problems {
+		fmt.Printf("✗ %v\n", problem)
+	}
+	if len(problems) == 0 {
+		fmt.Println("✓ Every nkey in the config has the expected key type")
+	}
+
 	fmt.Println("\n📖 Next Steps:")
 	fmt.Println("  1. Review the generated keys in:", keysFile)
 	fmt.Println("  2. Store the seeds (private keys) securely")
@@ -3,19 +3,39 @@ package examples
 import (
 	"encoding/base64"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/nats-io/nkeys"
 )
 
 type NKeyPair struct {
+	Type      nkeys.PrefixByte
 	Seed      string
 	PublicKey string
 }
 
-func GenerateUserNKey() (*NKeyPair, error) {
-	kp, err := nkeys.CreateUser()
+// NKeyTypes lists every key type that can be generated. Operators sign
+// accounts, accounts sign users, servers and clusters identify server nodes,
+// and curve keys encrypt payloads.
+var NKeyTypes = []nkeys.PrefixByte{
+	nkeys.PrefixByteOperator,
+	nkeys.PrefixByteAccount,
+	nkeys.PrefixByteUser,
+	nkeys.PrefixByteServer,
+	nkeys.PrefixByteCluster,
+	nkeys.PrefixByteCurve,
+}
+
+func GenerateNKey(keyType nkeys.PrefixByte) (*NKeyPair, error) {
+	if !isNKeyType(keyType) {
+		return nil, fmt.Errorf("cannot generate nkey of type %v", keyType)
+	}
+
+	kp, err := nkeys.CreatePair(keyType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user nkey: %w", err)
+		return nil, fmt.Errorf("failed to create %v nkey: %w", keyType, err)
 	}
 
 	seed, err := kp.Seed()
@@ -29,11 +49,84 @@ func GenerateUserNKey() (*NKeyPair, error) {
 	}
 
 	return &NKeyPair{
+		Type:      keyType,
 		Seed:      string(seed),
 		PublicKey: publicKey,
 	}, nil
 }
 
+func GenerateUserNKey() (*NKeyPair, error) {
+	return GenerateNKey(nkeys.PrefixByteUser)
+}
+
+// ParseNKeyType accepts a type name ("account") or its prefix letter ("A").
+func ParseNKeyType(name string) (nkeys.PrefixByte, error) {
+	if strings.EqualFold(name, "curve") || strings.EqualFold(name, "xkey") {
+		return nkeys.PrefixByteCurve, nil
+	}
+	for _, t := range NKeyTypes {
+		if strings.EqualFold(name, t.String()) || strings.EqualFold(name, nkeyPrefixLetter(t)) {
+			return t, nil
+		}
+	}
+	return nkeys.PrefixByteUnknown, fmt.Errorf("unknown nkey type %q (want operator, account, user, server, cluster or curve)", name)
+}
+
+// ValidatePublicKey checks that publicKey is a well-formed public key of the
+// expected type.
+func ValidatePublicKey(publicKey string, expected nkeys.PrefixByte) error {
+	if _, err := nkeys.Decode(expected, []byte(publicKey)); err != nil {
+		if nkeys.IsValidPublicKey(publicKey) {
+			return fmt.Errorf("%s has key type %v, expected %v", publicKey, nkeys.Prefix(publicKey), expected)
+		}
+		return fmt.Errorf("%s is not a valid %v public key: %w", publicKey, expected, err)
+	}
+	return nil
+}
+
+// ValidateSeed checks that seed is a well-formed seed for the expected key type.
+func ValidateSeed(seed string, expected nkeys.PrefixByte) error {
+	if len(seed) != nkeySeedLength {
+		return fmt.Errorf("invalid seed: %d characters, want %d", len(seed), nkeySeedLength)
+	}
+	prefix, _, err := nkeys.DecodeSeed([]byte(seed))
+	if err != nil {
+		return fmt.Errorf("invalid seed: %w", err)
+	}
+	if prefix != expected {
+		return fmt.Errorf("seed has key type %v, expected %v", prefix, expected)
+	}
+	return nil
+}
+
+func isNKeyType(keyType nkeys.PrefixByte) bool {
+	for _, t := range NKeyTypes {
+		if t == keyType {
+			return true
+		}
+	}
+	return false
+}
+
+// nkeyPrefixLetter returns the letter public keys of this type start with.
+func nkeyPrefixLetter(keyType nkeys.PrefixByte) string {
+	switch keyType {
+	case nkeys.PrefixByteOperator:
+		return "O"
+	case nkeys.PrefixByteAccount:
+		return "A"
+	case nkeys.PrefixByteUser:
+		return "U"
+	case nkeys.PrefixByteServer:
+		return "N"
+	case nkeys.PrefixByteCluster:
+		return "C"
+	case nkeys.PrefixByteCurve:
+		return "X"
+	}
+	return "?"
+}
+
 func SignChallenge(seed string, challenge []byte) ([]byte, error) {
 	kp, err := nkeys.FromSeed([]byte(seed))
 	if err != nil {
@@ -62,7 +155,7 @@ func VerifySignature(publicKey string, challenge, signature []byte) error {
 }
 
 func PrintNKeyPair(pair *NKeyPair, label string) {
-	fmt.Printf("\n%s NKey Pair:\n", label)
+	fmt.Printf("\n%s NKey Pair (%s, %s...):\n", label, pair.Type, nkeyPrefixLetter(pair.Type))
 	fmt.Printf("├─ Seed (Private Key):  %s\n", pair.Seed)
 	fmt.Printf("└─ Public Key:          %s\n", pair.PublicKey)
 	fmt.Println("\n⚠️  Keep the seed secret! Only share the public key.")
@@ -83,6 +176,32 @@ func DemoNKeyGeneration() {
 		PrintNKeyPair(pair, user)
 	}
 
+	fmt.Println("\n=== All NKey Types ===")
+	for _, keyType := range NKeyTypes {
+		pair, err := GenerateNKey(keyType)
+		if err != nil {
+			fmt.Printf("Error generating %v nkey: %v\n", keyType, err)
+			continue
+		}
+		fmt.Printf("  %-8s %s\n", keyType, pair.PublicKey)
+	}
+
+	fmt.Println("\n=== Key Type Validation ===")
+	fmt.Println("Placing an account key where a user key belongs...")
+	account, err := GenerateNKey(nkeys.PrefixByteAccount)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	misplaced := []GeneratedNKey{{Role: "Client", Type: account.Type, Seed: account.Seed, PublicKey: account.PublicKey}}
+	configFile := filepath.Join(os.TempDir(), "nkeys-misplaced.conf")
+	if err := GenerateServerConfig(misplaced, configFile); err != nil {
+		fmt.Printf("✓ Config generation correctly refused: %v\n", err)
+	} else {
+		fmt.Println("✗ Config was generated with an account key in a user nkey field")
+		os.Remove(configFile)
+	}
+
 	fmt.Println("\n=== Signature Demo ===")
 	fmt.Println("Demonstrating challenge-response authentication...")
 
@@ -0,0 +1,260 @@
+package examples
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+// XKeySenderHeader carries the sender's public curve key so the recipient
+// can open a sealed payload.
+const XKeySenderHeader = "Xkey-Sender"
+
+// CurveKey is an X25519 (xkey) key pair used to encrypt payloads. Curve keys
+// are separate from the Ed25519 user keys used for authentication.
+type CurveKey struct {
+	Role      string
+	Seed      string
+	PublicKey string
+}
+
+// XKeyDirectory maps roles to their public curve keys so senders can find
+// the key to seal a message for.
+type XKeyDirectory struct {
+	mu   sync.RWMutex
+	keys map[string]string
+}
+
+// GenerateCurveKey creates a curve key pair for a role.
+func GenerateCurveKey(role string) (*CurveKey, error) {
+	kp, err := nkeys.CreateCurveKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create curve key for %s: %w", role, err)
+	}
+
+	seed, err := kp.Seed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get curve seed for %s: %w", role, err)
+	}
+
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get curve public key for %s: %w", role, err)
+	}
+
+	return &CurveKey{
+		Role:      role,
+		Seed:      string(seed),
+		PublicKey: publicKey,
+	}, nil
+}
+
+// NewXKeyDirectory creates an empty directory.
+func NewXKeyDirectory() *XKeyDirectory {
+	return &XKeyDirectory{keys: map[string]string{}}
+}
+
+// Register publishes a role's public curve key.
+func (d *XKeyDirectory) Register(role, publicKey string) error {
+	if !nkeys.IsValidPublicCurveKey(publicKey) {
+		return fmt.Errorf("%q is not a curve public key", publicKey)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.keys[role] = publicKey
+	return nil
+}
+
+// Lookup returns the public curve key registered for a role.
+func (d *XKeyDirectory) Lookup(role string) (string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	publicKey, ok := d.keys[role]
+	if !ok {
+		return "", fmt.Errorf("no curve key registered for role %s", role)
+	}
+	return publicKey, nil
+}
+
+// SealPayload encrypts data so only the holder of recipient's seed can read it.
+func SealPayload(senderSeed, recipient string, data []byte) ([]byte, error) {
+	kp, err := nkeys.FromCurveSeed([]byte(senderSeed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse curve seed: %w", err)
+	}
+
+	sealed, err := kp.Seal(data, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal payload: %w", err)
+	}
+	return sealed, nil
+}
+
+// OpenPayload decrypts data sealed by sender for the holder of recipientSeed.
+func OpenPayload(recipientSeed, sender string, data []byte) ([]byte, error) {
+	kp, err := nkeys.FromCurveSeed([]byte(recipientSeed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse curve seed: %w", err)
+	}
+
+	opened, err := kp.Open(data, sender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open payload: %w", err)
+	}
+	return opened, nil
+}
+
+// SealMsg builds a message whose payload is sealed for recipient and whose
+// headers name the sender's public curve key.
+func SealMsg(sender *CurveKey, recipient, subject string, data []byte) (*nats.Msg, error) {
+	sealed, err := SealPayload(sender.Seed, recipient, data)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Header.Set(XKeySenderHeader, sender.PublicKey)
+	msg.Data = sealed
+	return msg, nil
+}
+
+// OpenMsg decrypts a message built by SealMsg. It also returns the sender's
+// public curve key, which is needed to seal a reply.
+func OpenMsg(recipient *CurveKey, msg *nats.Msg) ([]byte, string, error) {
+	sender := msg.Header.Get(XKeySenderHeader)
+	if sender == "" {
+		return nil, "", fmt.Errorf("message has no %s header", XKeySenderHeader)
+	}
+
+	data, err := OpenPayload(recipient.Seed, sender, msg.Data)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, sender, nil
+}
+
+// DemoNKeysEncryptedRequest repeats the Client/Service request-reply from
+// DemoNKeysAuth with payloads sealed using curve (xkey) keys
+func DemoNKeysEncryptedRequest() {
+	fmt.Println("\n=== Encrypted Request-Response with XKeys ===")
+
+	fmt.Println("\n1. Generating curve keys and registering them by role...")
+	directory := NewXKeyDirectory()
+	curveKeys := map[string]*CurveKey{}
+	for _, role := range []string{"Admin", "Client", "Service"} {
+		key, err := GenerateCurveKey(role)
+		if err != nil {
+			log.Printf("Curve key generation failed: %v", err)
+			return
+		}
+		if err := directory.Register(role, key.PublicKey); err != nil {
+			log.Printf("Curve key registration failed: %v", err)
+			return
+		}
+		curveKeys[role] = key
+		fmt.Printf("  %s: %s\n", role, key.PublicKey)
+	}
+
+	fmt.Println("\n2. Admin eavesdropping on '>'...")
+	adminNC, err := connectWithNKey(predefinedUsers[0])
+	if err != nil {
+		log.Printf("Admin connection failed: %v", err)
+		return
+	}
+	defer adminNC.Close()
+
+	observed, err := adminNC.SubscribeSync(">")
+	if err != nil {
+		log.Printf("Admin subscribe failed: %v", err)
+		return
+	}
+	defer observed.Unsubscribe()
+	adminNC.Flush()
+	fmt.Println("  ✓ Admin subscribed to '>'")
+
+	fmt.Println("\n3. Starting encrypted service responder...")
+	serviceNC, err := connectWithNKey(predefinedUsers[2])
+	if err != nil {
+		log.Printf("Service connection failed: %v", err)
+		return
+	}
+	defer serviceNC.Close()
+
+	serviceKey := curveKeys["Service"]
+	serviceSub, err := serviceNC.Subscribe("req.a", func(m *nats.Msg) {
+		request, sender, err := OpenMsg(serviceKey, m)
+		if err != nil {
+			fmt.Printf("  ✗ Service could not decrypt request: %v\n", err)
+			return
+		}
+		fmt.Printf("  ✓ Service decrypted request: %s\n", string(request))
+
+		reply, err := SealMsg(serviceKey, sender, m.Reply, []byte(fmt.Sprintf("Response to: %s", string(request))))
+		if err != nil {
+			fmt.Printf("  ✗ Service could not seal response: %v\n", err)
+			return
+		}
+		m.RespondMsg(reply)
+	})
+	if err != nil {
+		log.Printf("Service subscribe failed: %v", err)
+		return
+	}
+	defer serviceSub.Unsubscribe()
+	fmt.Println("  ✓ Service listening on 'req.a'")
+
+	fmt.Println("\n4. Client making encrypted request...")
+	clientNC, err := connectWithNKey(predefinedUsers[1])
+	if err != nil {
+		log.Printf("Client connection failed: %v", err)
+		return
+	}
+	defer clientNC.Close()
+
+	servicePublicKey, err := directory.Lookup("Service")
+	if err != nil {
+		log.Printf("Key discovery failed: %v", err)
+		return
+	}
+
+	clientKey := curveKeys["Client"]
+	request, err := SealMsg(clientKey, servicePublicKey, "req.a", []byte("Hello from client"))
+	if err != nil {
+		log.Printf("Seal failed: %v", err)
+		return
+	}
+
+	msg, err := clientNC.RequestMsg(request, 2*time.Second)
+	if err != nil {
+		log.Printf("  ✗ Request failed: %v", err)
+	} else if response, _, err := OpenMsg(clientKey, msg); err != nil {
+		fmt.Printf("  ✗ Client could not decrypt response: %v\n", err)
+	} else {
+		fmt.Printf("  ✓ Client decrypted response: %s\n", string(response))
+	}
+
+	fmt.Println("\n5. What the admin saw on the wire:")
+	for {
+		m, err := observed.NextMsg(500 * time.Millisecond)
+		if err != nil {
+			break
+		}
+		preview := base64.StdEncoding.EncodeToString(m.Data)
+		if len(preview) > 32 {
+			preview = preview[:32]
+		}
+		fmt.Printf("  %s: %d bytes of ciphertext (%s...)\n", m.Subject, len(m.Data), preview)
+		if _, _, err := OpenMsg(curveKeys["Admin"], m); err != nil {
+			fmt.Println("  ✓ Admin cannot decrypt it without the recipient's curve seed")
+		}
+	}
+
+	fmt.Println("\n=== Encrypted Request-Response Demo Complete ===")
+}
@@ -0,0 +1,576 @@
+package examples
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+// JSONWebKey is an RSA signing key from a JWKS document.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is the key set an OIDC provider publishes for verifying its tokens.
+type JWKS struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// LoadJWKS reads a JWKS document from an http(s) URL or a file. A URL that
+// does not answer within five seconds is an error.
+func LoadJWKS(source string) (*JWKS, error) {
+	var data []byte
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := &http.Client{Timeout: 5 * time.Second}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch JWKS: %s", resp.Status)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, fmt.Errorf("failed to read JWKS: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(source); err != nil {
+			return nil, fmt.Errorf("failed to read JWKS: %w", err)
+		}
+	}
+
+	var keys JWKS
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+	if len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("JWKS from %s has no keys", source)
+	}
+	return &keys, nil
+}
+
+// rsaKey returns the RSA public key with the given key ID.
+func (j *JWKS) rsaKey(kid string) (*rsa.PublicKey, error) {
+	for _, k := range j.Keys {
+		if k.Kid != kid || k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("bad modulus for key %q: %w", kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("bad exponent for key %q: %w", kid, err)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	}
+	return nil, fmt.Errorf("no RSA key %q in JWKS", kid)
+}
+
+// OIDCClaims are the claims of a verified OIDC token.
+type OIDCClaims map[string]interface{}
+
+// String returns a string claim, or "".
+func (c OIDCClaims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings returns a claim that may be a string or a list of strings.
+func (c OIDCClaims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// Expires returns the exp claim, or the zero time.
+func (c OIDCClaims) Expires() time.Time {
+	if exp, ok := c["exp"].(float64); ok {
+		return time.Unix(int64(exp), 0)
+	}
+	return time.Time{}
+}
+
+// VerifyOIDCToken checks an RS256 token's signature against keys and its
+// exp, nbf, iss and aud claims. Empty issuer or audience are not checked.
+func VerifyOIDCToken(token string, keys *JWKS, issuer, audience string) (OIDCClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token is not a JWT")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeTokenPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("bad token header: %w", err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+
+	key, err := keys.rsaKey(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("bad token signature: %w", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return nil, fmt.Errorf("token signature does not verify")
+	}
+
+	var claims OIDCClaims
+	if err := decodeTokenPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("bad token claims: %w", err)
+	}
+
+	now := time.Now()
+	if exp := claims.Expires(); exp.IsZero() || now.After(exp) {
+		return nil, fmt.Errorf("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("token not valid yet")
+	}
+	if issuer != "" && claims.String("iss") != issuer {
+		return nil, fmt.Errorf("token issuer %q is not trusted", claims.String("iss"))
+	}
+	if audience != "" && !containsString(claims.Strings("aud"), audience) {
+		return nil, fmt.Errorf("token is not for audience %q", audience)
+	}
+	return claims, nil
+}
+
+func decodeTokenPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// OIDCMapping maps token claims to a NATS account and role templates.
+type OIDCMapping struct {
+	TenantClaim string `json:"tenant_claim"`
+	GroupsClaim string `json:"groups_claim"`
+	// Tenants maps tenant claim values to account names.
+	Tenants map[string]string `json:"tenants"`
+	// Groups maps group claim values to role template names.
+	Groups map[string]string `json:"groups"`
+}
+
+// LoadOIDCMapping reads a claim mapping from a JSON file.
+func LoadOIDCMapping(filename string) (*OIDCMapping, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OIDC mapping: %w", err)
+	}
+
+	var m OIDCMapping
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse OIDC mapping: %w", err)
+	}
+	if m.TenantClaim == "" {
+		m.TenantClaim = "tenant"
+	}
+	if m.GroupsClaim == "" {
+		m.GroupsClaim = "groups"
+	}
+	return &m, nil
+}
+
+// Resolve returns the account and the merged permissions of every mapped
+// group in claims. At least one group must be mapped.
+func (m *OIDCMapping) Resolve(claims OIDCClaims, roles map[string]jwt.Permissions) (string, jwt.Permissions, error) {
+	var perms jwt.Permissions
+
+	tenant := claims.String(m.TenantClaim)
+	account, ok := m.Tenants[tenant]
+	if !ok {
+		return "", perms, fmt.Errorf("tenant %q is not mapped to an account", tenant)
+	}
+
+	var matched []string
+	for _, group := range claims.Strings(m.GroupsClaim) {
+		role, ok := m.Groups[group]
+		if !ok {
+			continue
+		}
+		tmpl, ok := roles[role]
+		if !ok {
+			return "", perms, fmt.Errorf("group %q maps to unknown role %q", group, role)
+		}
+		mergePermissions(&perms, tmpl)
+		matched = append(matched, role)
+	}
+	if len(matched) == 0 {
+		return "", perms, fmt.Errorf("none of the groups %v grant NATS access", claims.Strings(m.GroupsClaim))
+	}
+	return account, perms, nil
+}
+
+// mergePermissions adds src's allow and deny subjects to dst.
+func mergePermissions(dst *jwt.Permissions, src jwt.Permissions) {
+	dst.Pub.Allow.Add(src.Pub.Allow...)
+	dst.Pub.Deny.Add(src.Pub.Deny...)
+	dst.Sub.Allow.Add(src.Sub.Allow...)
+	dst.Sub.Deny.Add(src.Sub.Deny...)
+}
+
+// OIDCCallout is an auth callout that accepts OIDC access tokens in the
+// CONNECT token field and issues NATS user JWTs from their claims.
+type OIDCCallout struct {
+	Issuer      nkeys.KeyPair
+	JWKS        *JWKS
+	TokenIssuer string
+	Audience    string
+	Mapping     *OIDCMapping
+	Roles       map[string]jwt.Permissions
+	ExpiresIn   time.Duration
+
+	// Decisions, if set, is called with every accept or reject.
+	Decisions func(user string, err error)
+}
+
+// Start subscribes the callout service on an auth user connection.
+func (o *OIDCCallout) Start(nc *nats.Conn) (*nats.Subscription, error) {
+	return startCallout(nc, o.Issuer, o.ExpiresIn, o.Decisions, o.authorize)
+}
+
+func (o *OIDCCallout) authorize(req *jwt.AuthorizationRequestClaims) (*jwt.UserClaims, error) {
+	if req.ConnectOptions.Token == "" {
+		return nil, fmt.Errorf("no OIDC token presented")
+	}
+
+	claims, err := VerifyOIDCToken(req.ConnectOptions.Token, o.JWKS, o.TokenIssuer, o.Audience)
+	if err != nil {
+		return nil, err
+	}
+
+	account, perms, err := o.Mapping.Resolve(claims, o.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	uc := jwt.NewUserClaims(req.UserNkey)
+	uc.Name = claims.String("preferred_username")
+	if uc.Name == "" {
+		uc.Name = claims.String("sub")
+	}
+	uc.Audience = account
+	uc.Permissions = perms
+	// The NATS user never outlives the token it was issued for.
+	uc.Expires = claims.Expires().Unix()
+	if o.ExpiresIn > 0 {
+		if exp := time.Now().Add(o.ExpiresIn).Unix(); exp < uc.Expires {
+			uc.Expires = exp
+		}
+	}
+	return uc, nil
+}
+
+// LocalOIDCProvider is a stand-in identity provider that signs RS256 tokens
+// and serves its JWKS, so the OIDC callout can be tried without a real IdP.
+type LocalOIDCProvider struct {
+	Issuer string
+	Kid    string
+	key    *rsa.PrivateKey
+}
+
+// NewLocalOIDCProvider creates a provider with a fresh RSA signing key.
+func NewLocalOIDCProvider(issuer string) (*LocalOIDCProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signing key: %w", err)
+	}
+	return &LocalOIDCProvider{Issuer: issuer, Kid: fmt.Sprintf("demo-%d", time.Now().Unix()), key: key}, nil
+}
+
+// JWKS returns the provider's public key set.
+func (p *LocalOIDCProvider) JWKS() *JWKS {
+	return &JWKS{Keys: []JSONWebKey{{
+		Kty: "RSA",
+		Kid: p.Kid,
+		Alg: "RS256",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+	}}}
+}
+
+// Token signs an access token with the given claims. iss, iat and exp are
+// filled in from the provider and ttl.
+func (p *LocalOIDCProvider) Token(claims map[string]interface{}, ttl time.Duration) (string, error) {
+	body := map[string]interface{}{}
+	for k, v := range claims {
+		body[k] = v
+	}
+	now := time.Now()
+	body["iss"] = p.Issuer
+	body["iat"] = now.Unix()
+	body["exp"] = now.Add(ttl).Unix()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": p.Kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// ServeHTTP serves the JWKS document.
+func (p *LocalOIDCProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.JWKS())
+}
+
+// DemoOIDCCallout lands OIDC token holders in accounts A, B and C from
+// config/accounts.conf according to their tenant and group claims.
+func DemoOIDCCallout() {
+	fmt.Println("\n=== OIDC Auth Callout Demo ===")
+
+	fmt.Println("\n1. Starting a local identity provider and loading its JWKS:")
+	idp, err := NewLocalOIDCProvider("https://idp.demo.local")
+	if err != nil {
+		log.Printf("Failed to create identity provider: %v", err)
+		return
+	}
+	jwksServer := httptest.NewServer(idp)
+	defer jwksServer.Close()
+
+	keys, err := LoadJWKS(jwksServer.URL + "/.well-known/jwks.json")
+	if err != nil {
+		log.Printf("Failed to load JWKS: %v", err)
+		return
+	}
+	fmt.Printf("✓ Loaded %d signing key(s) from %s\n", len(keys.Keys), jwksServer.URL)
+
+	mapping, err := LoadOIDCMapping("config/oidc-mapping.json")
+	if err != nil {
+		log.Printf("Failed to load claim mapping: %v", err)
+		return
+	}
+	roles, err := LoadRoleTemplates("config/basic-auth.conf")
+	if err != nil {
+		log.Printf("Failed to load role templates: %v", err)
+		return
+	}
+	tenants := make([]string, 0, len(mapping.Tenants))
+	for tenant, account := range mapping.Tenants {
+		tenants = append(tenants, fmt.Sprintf("%s→%s", tenant, account))
+	}
+	sort.Strings(tenants)
+	fmt.Printf("✓ Tenant mapping: %s\n", strings.Join(tenants, ", "))
+
+	fmt.Println("\n2. Starting config/accounts.conf with an auth callout:")
+	opts, err := server.ProcessConfigFile("config/accounts.conf")
+	if err != nil {
+		log.Printf("Failed to load config/accounts.conf: %v", err)
+		return
+	}
+	opts.Port = server.RANDOM_PORT
+
+	issuer, err := nkeys.CreateAccount()
+	if err != nil {
+		log.Printf("Failed to create issuer key: %v", err)
+		return
+	}
+	issuerPub, _ := issuer.PublicKey()
+	if err := EnableAuthCallout(opts, issuerPub, "auth", "auth123"); err != nil {
+		log.Printf("Failed to enable auth callout: %v", err)
+		return
+	}
+
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+
+	authConn, err := nats.Connect(url, userPassword("auth", "auth123"))
+	if err != nil {
+		log.Printf("Auth user connection failed: %v", err)
+		return
+	}
+	defer authConn.Close()
+
+	var mu sync.Mutex
+	callout := &OIDCCallout{
+		Issuer:      issuer,
+		JWKS:        keys,
+		TokenIssuer: idp.Issuer,
+		Audience:    "nats",
+		Mapping:     mapping,
+		Roles:       roles,
+		Decisions: func(user string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fmt.Printf("  [callout] ✗ rejected %s: %v\n", user, err)
+			} else {
+				fmt.Printf("  [callout] ✓ accepted %s\n", user)
+			}
+		},
+	}
+	if _, err := callout.Start(authConn); err != nil {
+		log.Printf("Failed to start callout: %v", err)
+		return
+	}
+	authConn.Flush()
+	fmt.Println("✓ OIDC callout serving accounts A, B and C")
+
+	connect := func(user, tenant string, groups []string) (*nats.Conn, error) {
+		token, err := idp.Token(map[string]interface{}{
+			"sub":                user + "-id",
+			"preferred_username": user,
+			"aud":                "nats",
+			"tenant":             tenant,
+			"groups":             groups,
+		}, 10*time.Minute)
+		if err != nil {
+			return nil, err
+		}
+		return nats.Connect(url, nats.Token(token), nats.Name(user))
+	}
+
+	fmt.Println("\n3. Tenant claims choose the account:")
+	alice, err := connect("alice", "acme", []string{"nats-admins"})
+	if err != nil {
+		log.Printf("Alice connection failed: %v", err)
+		return
+	}
+	defer alice.Close()
+	dave, err := connect("dave", "acme", []string{"nats-admins"})
+	if err != nil {
+		log.Printf("Dave connection failed: %v", err)
+		return
+	}
+	defer dave.Close()
+	bob, err := connect("bob", "globex", []string{"nats-admins"})
+	if err != nil {
+		log.Printf("Bob connection failed: %v", err)
+		return
+	}
+	defer bob.Close()
+
+	sub, err := alice.SubscribeSync("orders.new")
+	if err != nil {
+		log.Printf("Alice subscribe failed: %v", err)
+		return
+	}
+	alice.Flush()
+
+	bob.Publish("orders.new", []byte("from globex"))
+	bob.Flush()
+	dave.Publish("orders.new", []byte("from acme"))
+	dave.Flush()
+
+	if msg, err := sub.NextMsg(time.Second); err == nil {
+		fmt.Printf("✓ Alice (acme→A) received %q from Dave in the same account\n", string(msg.Data))
+	}
+	if msg, err := sub.NextMsg(500 * time.Millisecond); err != nil {
+		fmt.Println("✓ Bob's message (globex→B) never reached account A")
+	} else {
+		fmt.Printf("❌ Alice received %q across accounts\n", string(msg.Data))
+	}
+
+	fmt.Println("\n4. Group claims choose the permissions:")
+	carol, err := connect("carol", "initech", []string{"requestors", "marketing"})
+	if err != nil {
+		log.Printf("Carol connection failed: %v", err)
+		return
+	}
+	defer carol.Close()
+	carolErrs := asyncErrors(carol)
+	if err := carol.Publish("req.a", []byte("request")); err == nil && carol.Flush() == nil && waitError(carolErrs, 500*time.Millisecond) == nil {
+		fmt.Println("✓ Carol (initech→C, REQUESTOR) published to 'req.a'")
+	}
+	carol.Publish("orders.new", []byte("not allowed"))
+	carol.Flush()
+	if err := waitError(carolErrs, time.Second); err != nil {
+		fmt.Printf("✗ Carol correctly denied publishing to 'orders.new': %v\n", err)
+	}
+
+	fmt.Println("\n5. Tokens the callout rejects:")
+	if _, err := connect("eve", "umbrella", []string{"nats-admins"}); err != nil {
+		fmt.Printf("✗ Unmapped tenant rejected: %v\n", err)
+	}
+	if _, err := connect("frank", "acme", []string{"marketing"}); err != nil {
+		fmt.Printf("✗ No NATS group rejected: %v\n", err)
+	}
+	expired, _ := idp.Token(map[string]interface{}{"sub": "gina", "aud": "nats", "tenant": "acme", "groups": []string{"nats-admins"}}, -time.Minute)
+	if _, err := nats.Connect(url, nats.Token(expired)); err != nil {
+		fmt.Printf("✗ Expired token rejected: %v\n", err)
+	}
+	rogue, _ := NewLocalOIDCProvider(idp.Issuer)
+	rogue.Kid = idp.Kid
+	forged, _ := rogue.Token(map[string]interface{}{"sub": "mallory", "aud": "nats", "tenant": "acme", "groups": []string{"nats-admins"}}, time.Minute)
+	if _, err := nats.Connect(url, nats.Token(forged)); err != nil {
+		fmt.Printf("✗ Token signed by another key rejected: %v\n", err)
+	}
+
+	fmt.Println("\n=== OIDC Auth Callout Demo Complete ===")
+}
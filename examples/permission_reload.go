@@ -0,0 +1,349 @@
+package examples
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// Outcomes of a PermissionObservation.
+const (
+	// OutcomeKept: an existing subscription still receives the probe.
+	OutcomeKept = "kept"
+	// OutcomeFiltered: the subscription survived, but the probe subject is
+	// no longer delivered to it.
+	OutcomeFiltered = "filtered"
+	// OutcomeDropped: the server removed the subscription.
+	OutcomeDropped = "dropped"
+	OutcomeAllowed = "allowed"
+	OutcomeDenied  = "denied"
+)
+
+// PermissionObservation is one operation of the limited user, checked
+// before or after its permissions are reloaded.
+type PermissionObservation struct {
+	// Phase is "before" or "after" the reload.
+	Phase string `json:"phase"`
+	// Operation is "subscription" for a subscription made before the
+	// reload, or "publish", "subscribe" or "connection".
+	Operation string `json:"operation"`
+	Subject   string `json:"subject,omitempty"`
+	Queue     string `json:"queue,omitempty"`
+	// Probe is the subject published to test a subscription.
+	Probe   string `json:"probe,omitempty"`
+	Outcome string `json:"outcome"`
+	// Error is the error the server reported for the operation, if any.
+	Error string `json:"error,omitempty"`
+}
+
+func (o PermissionObservation) String() string {
+	what := o.Operation + " " + o.Subject
+	if o.Queue != "" {
+		what += " (queue " + o.Queue + ")"
+	}
+	if o.Probe != "" && o.Probe != o.Subject {
+		what += " <- " + o.Probe
+	}
+	line := fmt.Sprintf("%-6s %-72s %s", o.Phase, what, o.Outcome)
+	if o.Error != "" {
+		line += ": " + o.Error
+	}
+	return line
+}
+
+// reloadSubscription is a subscription the limited user holds across the
+// reload, with the subjects published to test it.
+type reloadSubscription struct {
+	subject, queue string
+	probes         []string
+}
+
+var reloadSubscriptions = []reloadSubscription{
+	{subject: "client.notifications", probes: []string{"client.notifications"}},
+	{subject: "client.>", probes: []string{"client.status", "client.internal.audit"}},
+	{subject: "client.jobs", queue: "workers", probes: []string{"client.jobs"}},
+	{subject: "client.internal.audit", probes: []string{"client.internal.audit"}},
+	{subject: "events.>", probes: []string{"events.user.login"}},
+	{subject: "events.user.*", queue: "auditors", probes: []string{"events.user.logout"}},
+}
+
+// NarrowedLimitedPermissions are the permissions the limited user of
+// config/allow-deny.conf is reloaded with: events.> is gone from both
+// lists, and public.secret and client.internal.> are denied.
+func NarrowedLimitedPermissions() *server.Permissions {
+	return &server.Permissions{
+		Publish: &server.SubjectPermission{
+			Allow: []string{"public.>"},
+			Deny:  []string{"public.secret"},
+		},
+		Subscribe: &server.SubjectPermission{
+			Allow: []string{"client.>"},
+			Deny:  []string{"client.internal.>"},
+		},
+	}
+}
+
+// permissionError returns the first error that names subject.
+func permissionError(errs []error, subject string) string {
+	quoted := fmt.Sprintf("%q", subject)
+	for _, err := range errs {
+		if strings.Contains(err.Error(), quoted) {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// drainErrors collects the errors that arrive on errs within wait.
+func drainErrors(errs <-chan error, wait time.Duration) []error {
+	var out []error
+	for {
+		err := waitError(errs, wait)
+		if err == nil {
+			return out
+		}
+		out = append(out, err)
+	}
+}
+
+// ObservePermissionReload runs configFile, which must define the limited
+// user of config/allow-deny.conf, in an embedded server. It subscribes as
+// limited, reloads the server with perms for limited, and reports what
+// happened to each subscription and to new operations.
+func ObservePermissionReload(configFile string, perms *server.Permissions) ([]PermissionObservation, error) {
+	opts, err := server.ProcessConfigFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", configFile, err)
+	}
+	opts.Port = server.RANDOM_PORT
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+
+	admin, err := nats.Connect(url, userPassword("admin", "admin123"))
+	if err != nil {
+		return nil, fmt.Errorf("admin connection failed: %w", err)
+	}
+	defer admin.Close()
+	limited, err := nats.Connect(url, userPassword("limited", "limited123"))
+	if err != nil {
+		return nil, fmt.Errorf("limited connection failed: %w", err)
+	}
+	defer limited.Close()
+	errs := asyncErrors(limited)
+
+	subs := make([]*nats.Subscription, len(reloadSubscriptions))
+	for i, rs := range reloadSubscriptions {
+		if rs.queue != "" {
+			subs[i], err = limited.QueueSubscribeSync(rs.subject, rs.queue)
+		} else {
+			subs[i], err = limited.SubscribeSync(rs.subject)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to subscribe to %s: %w", rs.subject, err)
+		}
+	}
+	if err := limited.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to flush subscriptions: %w", err)
+	}
+
+	// Wildcard subscriptions also receive other subscriptions' probes, so
+	// each probe carries a unique payload.
+	probes := 0
+	received := func(sub *nats.Subscription, probe string) bool {
+		probes++
+		payload := fmt.Sprintf("probe %d", probes)
+		admin.Publish(probe, []byte(payload))
+		admin.Flush()
+		deadline := time.Now().Add(300 * time.Millisecond)
+		for {
+			msg, err := sub.NextMsg(time.Until(deadline))
+			if err != nil {
+				return false
+			}
+			if string(msg.Data) == payload {
+				return true
+			}
+		}
+	}
+
+	var observations []PermissionObservation
+	observe := func(o PermissionObservation) {
+		observations = append(observations, o)
+	}
+
+	for i, rs := range reloadSubscriptions {
+		for _, probe := range rs.probes {
+			o := PermissionObservation{Phase: "before", Operation: "subscription", Subject: rs.subject, Queue: rs.queue, Probe: probe, Outcome: OutcomeAllowed}
+			if !received(subs[i], probe) {
+				o.Outcome = OutcomeDenied
+			}
+			observe(o)
+		}
+	}
+	drainErrors(errs, 100*time.Millisecond)
+
+	newOpts, err := server.ProcessConfigFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", configFile, err)
+	}
+	found := false
+	for _, u := range newOpts.Users {
+		if u.Username == "limited" {
+			u.Permissions = perms
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s has no limited user", configFile)
+	}
+	if err := reloadEmbeddedServer(s, newOpts); err != nil {
+		return nil, err
+	}
+	reloadErrs := drainErrors(errs, 300*time.Millisecond)
+
+	for i, rs := range reloadSubscriptions {
+		results := make([]bool, len(rs.probes))
+		live := false
+		for j, probe := range rs.probes {
+			results[j] = received(subs[i], probe)
+			live = live || results[j]
+		}
+		msg := permissionError(reloadErrs, rs.subject)
+		for j, probe := range rs.probes {
+			o := PermissionObservation{Phase: "after", Operation: "subscription", Subject: rs.subject, Queue: rs.queue, Probe: probe, Error: msg}
+			switch {
+			case results[j]:
+				o.Outcome = OutcomeKept
+			case live && msg == "":
+				o.Outcome = OutcomeFiltered
+			default:
+				o.Outcome = OutcomeDropped
+			}
+			observe(o)
+		}
+	}
+
+	for _, subject := range []string{"public.news", "public.secret", "events.user.login"} {
+		check, _ := admin.SubscribeSync(subject)
+		admin.Flush()
+		limited.Publish(subject, []byte("after reload"))
+		limited.Flush()
+		o := PermissionObservation{Phase: "after", Operation: "publish", Subject: subject, Outcome: OutcomeDenied}
+		if _, err := check.NextMsg(300 * time.Millisecond); err == nil {
+			o.Outcome = OutcomeAllowed
+		}
+		o.Error = permissionError(drainErrors(errs, 100*time.Millisecond), subject)
+		check.Unsubscribe()
+		observe(o)
+	}
+
+	for _, subject := range []string{"client.updates", "client.internal.logs", "events.new"} {
+		o := PermissionObservation{Phase: "after", Operation: "subscribe", Subject: subject, Outcome: OutcomeDenied}
+		sub, err := limited.SubscribeSync(subject)
+		if err != nil {
+			o.Error = err.Error()
+		} else {
+			limited.Flush()
+			if received(sub, subject) {
+				o.Outcome = OutcomeAllowed
+			}
+			o.Error = permissionError(drainErrors(errs, 100*time.Millisecond), subject)
+		}
+		observe(o)
+	}
+
+	o := PermissionObservation{Phase: "after", Operation: "connection", Outcome: OutcomeKept}
+	if !limited.IsConnected() {
+		o.Outcome = OutcomeDropped
+		if err := limited.LastError(); err != nil {
+			o.Error = err.Error()
+		}
+	}
+	observe(o)
+	return observations, nil
+}
+
+// printPermissions prints the publish and subscribe lists of p.
+func printPermissions(p *server.Permissions) {
+	line := func(label string, sp *server.SubjectPermission) {
+		if sp == nil {
+			fmt.Printf("   %-10s (any)\n", label+":")
+			return
+		}
+		text := "allow " + strings.Join(sp.Allow, ", ")
+		if len(sp.Allow) == 0 {
+			text = "allow >"
+		}
+		if len(sp.Deny) > 0 {
+			text += "  deny " + strings.Join(sp.Deny, ", ")
+		}
+		fmt.Printf("   %-10s %s\n", label+":", text)
+	}
+	line("publish", p.Publish)
+	line("subscribe", p.Subscribe)
+}
+
+// DemoPermissionReload demonstrates what a config reload that narrows a
+// user's permissions does to its live subscriptions and new operations
+func DemoPermissionReload() {
+	fmt.Println("\n=== Hot-Reload Permission Change Demo ===")
+
+	opts, err := server.ProcessConfigFile("config/allow-deny.conf")
+	if err != nil {
+		log.Printf("Failed to load config/allow-deny.conf: %v", err)
+		return
+	}
+	var before *server.Permissions
+	for _, u := range opts.Users {
+		if u.Username == "limited" {
+			before = u.Permissions
+		}
+	}
+	if before == nil {
+		log.Printf("config/allow-deny.conf has no permissions for limited")
+		return
+	}
+
+	perms := NarrowedLimitedPermissions()
+	fmt.Println("\n1. limited's permissions in config/allow-deny.conf:")
+	printPermissions(before)
+	fmt.Println("   ...reloaded with:")
+	printPermissions(perms)
+
+	observations, err := ObservePermissionReload("config/allow-deny.conf", perms)
+	if err != nil {
+		log.Printf("Permission reload failed: %v", err)
+		return
+	}
+
+	fmt.Println("\n2. Observations (admin publishes each probe):")
+	for _, o := range observations {
+		mark := "✓"
+		switch o.Outcome {
+		case OutcomeDropped, OutcomeDenied:
+			mark = "✗"
+		case OutcomeFiltered:
+			mark = "~"
+		}
+		fmt.Printf("%s %s\n", mark, o)
+	}
+
+	counts := map[string]int{}
+	for _, o := range observations {
+		if o.Phase == "after" && o.Operation == "subscription" {
+			counts[o.Outcome]++
+		}
+	}
+	fmt.Printf("\n3. After the reload: %d probes kept, %d filtered, %d dropped across %d subscriptions\n",
+		counts[OutcomeKept], counts[OutcomeFiltered], counts[OutcomeDropped], len(reloadSubscriptions))
+
+	fmt.Println("\n=== Hot-Reload Permission Change Demo Complete ===")
+}
@@ -0,0 +1,518 @@
+package examples
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"golang.org/x/crypto/scrypt"
+)
+
+// SecretProvider resolves secret references of one scheme, for example
+// "env:ADMIN_SEED" is resolved by the "env" provider with name "ADMIN_SEED".
+type SecretProvider interface {
+	Scheme() string
+	Resolve(name string) (string, error)
+}
+
+// knownSecretSchemes are the reference schemes this package provides, with
+// how to configure the ones that need setup. A reference to one of them is
+// never passed through as a literal, even if no provider is registered.
+var knownSecretSchemes = map[string]string{
+	"env":     "",
+	"file":    "",
+	"keyring": "set NATS_DEMO_KEYRING and NATS_DEMO_KEYRING_PASSPHRASE",
+	"vault":   "set VAULT_ADDR and VAULT_TOKEN",
+}
+
+// SecretResolver turns secret references into secret values using the
+// provider registered for the reference's scheme. Values without a known
+// scheme are returned unchanged, so literal seeds and passwords keep working.
+type SecretResolver struct {
+	mu        sync.RWMutex
+	providers map[string]SecretProvider
+}
+
+// NewSecretResolver creates a resolver with the given providers.
+func NewSecretResolver(providers ...SecretProvider) *SecretResolver {
+	r := &SecretResolver{providers: map[string]SecretProvider{}}
+	for _, p := range providers {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register adds or replaces the provider for p.Scheme().
+func (r *SecretResolver) Register(p SecretProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Scheme()] = p
+}
+
+// Resolve returns the secret a reference points to, or the value itself if
+// it is not a reference.
+func (r *SecretResolver) Resolve(value string) (string, error) {
+	scheme, name, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+
+	r.mu.RLock()
+	p, ok := r.providers[scheme]
+	r.mu.RUnlock()
+	if !ok {
+		hint, known := knownSecretSchemes[scheme]
+		if !known {
+			return value, nil
+		}
+		if hint != "" {
+			return "", fmt.Errorf("no %s provider is configured for secret %q (%s)", scheme, name, hint)
+		}
+		return "", fmt.Errorf("no %s provider is configured for secret %q", scheme, name)
+	}
+
+	secret, err := p.Resolve(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s secret %q: %w", scheme, name, err)
+	}
+	return secret, nil
+}
+
+// IsReference reports whether value names a known or registered scheme.
+func (r *SecretResolver) IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	if _, known := knownSecretSchemes[scheme]; known {
+		return true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok = r.providers[scheme]
+	return ok
+}
+
+// DefaultSecrets resolves the references used by the demos. It knows the
+// env and file schemes; keyring and vault are added when their environment
+// variables are set (see NewKeyringFromEnv and NewVaultFromEnv).
+var DefaultSecrets = defaultSecretResolver()
+
+func defaultSecretResolver() *SecretResolver {
+	r := NewSecretResolver(EnvSecretProvider{}, FileSecretProvider{})
+	kr, err := NewKeyringFromEnv()
+	switch {
+	case err != nil:
+		log.Printf("Keyring secrets disabled: %v", err)
+	case kr != nil:
+		r.Register(kr)
+	}
+	if v := NewVaultFromEnv(); v != nil {
+		r.Register(v)
+	}
+	return r
+}
+
+// EnvSecretProvider reads secrets from environment variables: "env:NAME".
+type EnvSecretProvider struct{}
+
+func (EnvSecretProvider) Scheme() string { return "env" }
+
+func (EnvSecretProvider) Resolve(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable is not set")
+	}
+	return value, nil
+}
+
+// FileSecretProvider reads secrets from files, such as mounted container
+// secrets: "file:/run/secrets/client.nk". Surrounding whitespace is trimmed.
+type FileSecretProvider struct{}
+
+func (FileSecretProvider) Scheme() string { return "file" }
+
+func (FileSecretProvider) Resolve(name string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// KeyringSecretProvider stores secrets in a passphrase-encrypted file:
+// "keyring:admin-seed". Each entry is sealed with AES-256-GCM under a key
+// derived from the passphrase with scrypt.
+type KeyringSecretProvider struct {
+	Path       string
+	passphrase []byte
+}
+
+type keyringFile struct {
+	Salt    []byte            `json:"salt"`
+	Entries map[string][]byte `json:"entries"`
+}
+
+// NewKeyring opens (or prepares to create) the keyring at path.
+func NewKeyring(path, passphrase string) *KeyringSecretProvider {
+	return &KeyringSecretProvider{Path: path, passphrase: []byte(passphrase)}
+}
+
+// NewKeyringFromEnv opens the keyring named by NATS_DEMO_KEYRING using the
+// passphrase in NATS_DEMO_KEYRING_PASSPHRASE. It returns nil if no keyring
+// is configured.
+func NewKeyringFromEnv() (*KeyringSecretProvider, error) {
+	path := os.Getenv("NATS_DEMO_KEYRING")
+	if path == "" {
+		return nil, nil
+	}
+	passphrase, ok := os.LookupEnv("NATS_DEMO_KEYRING_PASSPHRASE")
+	if !ok {
+		return nil, fmt.Errorf("NATS_DEMO_KEYRING_PASSPHRASE is not set")
+	}
+	return NewKeyring(path, passphrase), nil
+}
+
+func (k *KeyringSecretProvider) Scheme() string { return "keyring" }
+
+func (k *KeyringSecretProvider) Resolve(name string) (string, error) {
+	kf, err := k.load()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, ok := kf.Entries[name]
+	if !ok {
+		return "", fmt.Errorf("no keyring entry named %s", name)
+	}
+
+	aead, err := k.cipher(kf.Salt)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("keyring entry %s is corrupt", name)
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt keyring entry %s (wrong passphrase?)", name)
+	}
+	return string(plain), nil
+}
+
+// Set encrypts value into the keyring under name, creating the file if needed.
+func (k *KeyringSecretProvider) Set(name, value string) error {
+	kf, err := k.load()
+	if os.IsNotExist(err) {
+		kf = &keyringFile{Salt: make([]byte, 16), Entries: map[string][]byte{}}
+		if _, err := io.ReadFull(rand.Reader, kf.Salt); err != nil {
+			return fmt.Errorf("failed to create keyring salt: %w", err)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	aead, err := k.cipher(kf.Salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to create nonce: %w", err)
+	}
+	kf.Entries[name] = aead.Seal(nonce, nonce, []byte(value), []byte(name))
+
+	data, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode keyring: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(k.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(k.Path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write keyring: %w", err)
+	}
+	return nil
+}
+
+func (k *KeyringSecretProvider) load() (*keyringFile, error) {
+	data, err := os.ReadFile(k.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kf keyringFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring: %w", err)
+	}
+	if kf.Entries == nil {
+		kf.Entries = map[string][]byte{}
+	}
+	return &kf, nil
+}
+
+func (k *KeyringSecretProvider) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(k.passphrase, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive keyring key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// VaultSecretProvider reads secrets from a Vault-compatible KV HTTP API:
+// "vault:secret/data/nats#admin_seed" reads field admin_seed from the
+// secret at path secret/data/nats. Both KV v1 and v2 responses are accepted.
+type VaultSecretProvider struct {
+	Address string
+	Token   string
+	Client  *http.Client
+}
+
+// NewVaultFromEnv configures a provider from VAULT_ADDR and VAULT_TOKEN. It
+// returns nil if VAULT_ADDR is not set.
+func NewVaultFromEnv() *VaultSecretProvider {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil
+	}
+	return &VaultSecretProvider{Address: addr, Token: os.Getenv("VAULT_TOKEN")}
+}
+
+func (v *VaultSecretProvider) Scheme() string { return "vault" }
+
+func (v *VaultSecretProvider) Resolve(name string) (string, error) {
+	path, field, ok := strings.Cut(name, "#")
+	if !ok || field == "" {
+		return "", fmt.Errorf("vault reference must be <path>#<field>")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(v.Address, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+
+	client := v.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse vault response: %w", err)
+	}
+
+	fields := body.Data
+	if nested, ok := body.Data["data"]; ok {
+		// KV v2 wraps the secret in data.data alongside data.metadata.
+		var inner map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &inner); err == nil {
+			fields = inner
+		}
+	}
+
+	raw, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("field %s not found", field)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("field %s is not a string", field)
+	}
+	return value, nil
+}
+
+// userPassword authenticates with a user name and password. The password
+// may be a secret reference such as "env:ADMIN_PASSWORD".
+func userPassword(user, password string) nats.Option {
+	return resolvedUserPassword(DefaultSecrets, user, password)
+}
+
+// resolvedUserPassword is userPassword with references resolved by secrets.
+func resolvedUserPassword(secrets *SecretResolver, user, password string) nats.Option {
+	return func(o *nats.Options) error {
+		secret, err := secrets.Resolve(password)
+		if err != nil {
+			return err
+		}
+		o.User = user
+		o.Password = secret
+		return nil
+	}
+}
+
+// tokenOption authenticates with a token. A secret reference such as
+// "env:NATS_TOKEN" is resolved again on every connect and reconnect, so a
+// rotated token is picked up without restarting the client.
+func tokenOption(token string) nats.Option {
+	if !DefaultSecrets.IsReference(token) {
+		return nats.Token(token)
+	}
+	return nats.TokenHandler(func() string {
+		secret, err := DefaultSecrets.Resolve(token)
+		if err != nil {
+			log.Printf("Token lookup failed: %v", err)
+			return ""
+		}
+		return secret
+	})
+}
+
+// DemoSecretProviders demonstrates connecting with seeds and passwords that
+// are resolved by reference instead of being written in source
+func DemoSecretProviders() {
+	fmt.Println("\n=== Secret Providers Demo ===")
+
+	dir, err := os.MkdirTemp("", "nats-secrets-")
+	if err != nil {
+		log.Printf("Failed to create temp dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Println("\n1. Generating one user nkey per provider...")
+	refs := []string{
+		"env:NATS_DEMO_ENV_SEED",
+		"file:" + filepath.Join(dir, "client.nk"),
+		"keyring:service-seed",
+		"vault:secret/data/nats#admin_seed",
+	}
+	pairs := make([]*NKeyPair, len(refs))
+	var nkeyUsers []*server.NkeyUser
+	for i := range refs {
+		pairs[i], err = GenerateUserNKey()
+		if err != nil {
+			log.Printf("Key generation failed: %v", err)
+			return
+		}
+		nkeyUsers = append(nkeyUsers, &server.NkeyUser{Nkey: pairs[i].PublicKey})
+	}
+
+	srv, err := startEmbeddedServer(&server.Options{
+		Nkeys: nkeyUsers,
+		Users: []*server.User{{Username: "app", Password: "app-secret"}},
+	})
+	if err != nil {
+		log.Printf("Embedded server failed: %v", err)
+		return
+	}
+	defer srv.Shutdown()
+	url := srv.ClientURL()
+	fmt.Printf("  ✓ Embedded server at %s trusts %d nkeys and user 'app'\n", url, len(nkeyUsers))
+
+	fmt.Println("\n2. Storing the secrets...")
+	os.Setenv("NATS_DEMO_ENV_SEED", pairs[0].Seed)
+	defer os.Unsetenv("NATS_DEMO_ENV_SEED")
+	os.Setenv("NATS_DEMO_APP_PASSWORD", "app-secret")
+	defer os.Unsetenv("NATS_DEMO_APP_PASSWORD")
+	fmt.Println("  ✓ env: NATS_DEMO_ENV_SEED, NATS_DEMO_APP_PASSWORD")
+
+	if err := os.WriteFile(filepath.Join(dir, "client.nk"), []byte(pairs[1].Seed+"\n"), 0600); err != nil {
+		log.Printf("Write seed file failed: %v", err)
+		return
+	}
+	fmt.Println("  ✓ file: client.nk")
+
+	keyring := NewKeyring(filepath.Join(dir, "keyring.json"), "correct horse battery staple")
+	if err := keyring.Set("service-seed", pairs[2].Seed); err != nil {
+		log.Printf("Keyring write failed: %v", err)
+		return
+	}
+	fmt.Println("  ✓ keyring: service-seed (AES-256-GCM, scrypt passphrase)")
+
+	vaultToken := "demo-root-token"
+	vaultStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != vaultToken {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/nats" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     map[string]string{"admin_seed": pairs[3].Seed},
+				"metadata": map[string]interface{}{"version": 1},
+			},
+		})
+	}))
+	defer vaultStub.Close()
+	fmt.Printf("  ✓ vault: secret/data/nats#admin_seed (KV v2 stub at %s)\n", vaultStub.URL)
+
+	// A local resolver keeps the demo's keyring and vault stub out of
+	// DefaultSecrets, which outlives the demo.
+	secrets := NewSecretResolver(EnvSecretProvider{}, FileSecretProvider{}, keyring,
+		&VaultSecretProvider{Address: vaultStub.URL, Token: vaultToken})
+
+	fmt.Println("\n3. Connecting with seeds resolved by reference:")
+	for _, ref := range refs {
+		nc, err := nats.Connect(url, resolvedNKeyOption(secrets, ref), nats.Timeout(2*time.Second))
+		if err != nil {
+			fmt.Printf("  ✗ %s: %v\n", ref, err)
+			continue
+		}
+		fmt.Printf("  ✓ Connected with %s\n", ref)
+		nc.Close()
+	}
+
+	nc, err := nats.Connect(url, resolvedUserPassword(secrets, "app", "env:NATS_DEMO_APP_PASSWORD"))
+	if err != nil {
+		fmt.Printf("  ✗ app with env:NATS_DEMO_APP_PASSWORD: %v\n", err)
+	} else {
+		fmt.Println("  ✓ Connected as 'app' with password env:NATS_DEMO_APP_PASSWORD")
+		nc.Close()
+	}
+
+	fmt.Println("\n4. Failures are reported without revealing the secret:")
+	failures := []struct {
+		label    string
+		resolver *SecretResolver
+		ref      string
+	}{
+		{"unset variable", secrets, "env:NATS_DEMO_MISSING"},
+		{"missing file", secrets, "file:" + filepath.Join(dir, "missing.nk")},
+		{"wrong passphrase", NewSecretResolver(NewKeyring(keyring.Path, "wrong")), "keyring:service-seed"},
+		{"wrong vault token", NewSecretResolver(&VaultSecretProvider{Address: vaultStub.URL, Token: "bad"}), "vault:secret/data/nats#admin_seed"},
+	}
+	for _, f := range failures {
+		if _, err := f.resolver.Resolve(f.ref); err != nil {
+			fmt.Printf("  ✓ %s: %v\n", f.label, err)
+		} else {
+			fmt.Printf("  ✗ %s: unexpectedly resolved\n", f.label)
+		}
+	}
+
+	fmt.Println("\n=== Secret Providers Demo Complete ===")
+}
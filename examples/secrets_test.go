@@ -0,0 +1,135 @@
+package examples
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// vaultStub serves secret/data/nats in KV v2 form and kv/nats in KV v1
+// form to requests carrying token.
+func vaultStub(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/nats":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]interface{}{"admin_seed": "SUAV2SEED", "port": 4222},
+					"metadata": map[string]interface{}{"version": 3},
+				},
+			})
+		case "/v1/kv/nats":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"admin_seed": "SUAV1SEED"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	srv := vaultStub(t, "root")
+
+	tests := []struct {
+		name    string
+		token   string
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{name: "kv v2", token: "root", ref: "secret/data/nats#admin_seed", want: "SUAV2SEED"},
+		{name: "kv v1", token: "root", ref: "kv/nats#admin_seed", want: "SUAV1SEED"},
+		{name: "leading slash", token: "root", ref: "/secret/data/nats#admin_seed", want: "SUAV2SEED"},
+		{name: "wrong token", token: "bad", ref: "secret/data/nats#admin_seed", wantErr: "403"},
+		{name: "unknown path", token: "root", ref: "secret/data/other#admin_seed", wantErr: "404"},
+		{name: "missing field", token: "root", ref: "secret/data/nats#user_seed", wantErr: "field user_seed not found"},
+		{name: "non-string field", token: "root", ref: "secret/data/nats#port", wantErr: "field port is not a string"},
+		{name: "no field", token: "root", ref: "secret/data/nats", wantErr: "<path>#<field>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &VaultSecretProvider{Address: srv.URL + "/", Token: tt.token}
+			got, err := v.Resolve(tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve(%q) error = %v, want containing %q", tt.ref, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) failed: %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Fatalf("Resolve(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretResolverVaultReference(t *testing.T) {
+	srv := vaultStub(t, "root")
+	r := NewSecretResolver(&VaultSecretProvider{Address: srv.URL, Token: "root"})
+
+	got, err := r.Resolve("vault:secret/data/nats#admin_seed")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if got != "SUAV2SEED" {
+		t.Fatalf("Resolve = %q, want SUAV2SEED", got)
+	}
+
+	_, err = r.Resolve("vault:secret/data/nats#missing")
+	if err == nil {
+		t.Fatal("Resolve of a missing field succeeded")
+	}
+	if strings.Contains(err.Error(), "SUAV2SEED") {
+		t.Fatalf("error reveals the secret: %v", err)
+	}
+
+	if got, _ := r.Resolve("SULITERAL"); got != "SULITERAL" {
+		t.Fatalf("literal value changed to %q", got)
+	}
+}
+
+func TestSecretResolverUnconfiguredScheme(t *testing.T) {
+	r := NewSecretResolver(EnvSecretProvider{})
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{value: "vault:secret/admin#password", wantErr: "VAULT_ADDR"},
+		{value: "keyring:admin", wantErr: "NATS_DEMO_KEYRING"},
+		{value: "file:/etc/nats/admin.nk", wantErr: "no file provider"},
+		{value: "admin123", want: "admin123"},
+		{value: "https://example.com", want: "https://example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if !r.IsReference(tt.value) && tt.wantErr != "" {
+				t.Errorf("IsReference(%q) = false for a known scheme", tt.value)
+			}
+			got, err := r.Resolve(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve(%q) = %q, %v, want error containing %q", tt.value, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Resolve(%q) = %q, %v, want %q", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
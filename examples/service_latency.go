@@ -0,0 +1,296 @@
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// LatencyStats summarises the latency samples of one importing account
+// for one service.
+type LatencyStats struct {
+	// Results is the subject the samples were published to.
+	Results  string
+	Importer string
+	Samples  int
+	// Errors counts samples whose status was not 200, e.g. a request
+	// without a reply subject.
+	Errors int
+	// Percentiles of the total latency, as seen by the requestor.
+	P50, P90, P99, Max time.Duration
+	// ServiceP50 is the median time spent in the responder.
+	ServiceP50 time.Duration
+}
+
+type latencyKey struct {
+	results, importer string
+}
+
+// LatencyCollector subscribes to the results subjects of latency-tracked
+// service exports and groups the samples by importing account. It must
+// connect as a user of the exporting account.
+type LatencyCollector struct {
+	mu      sync.Mutex
+	subs    []*nats.Subscription
+	samples map[latencyKey][]server.ServiceLatency
+}
+
+// NewLatencyCollector subscribes to each results subject on nc.
+func NewLatencyCollector(nc *nats.Conn, results ...string) (*LatencyCollector, error) {
+	c := &LatencyCollector{samples: map[latencyKey][]server.ServiceLatency{}}
+	for _, subject := range results {
+		sub, err := nc.Subscribe(subject, c.record)
+		if err != nil {
+			c.Stop()
+			return nil, fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+		}
+		c.subs = append(c.subs, sub)
+	}
+	if err := nc.Flush(); err != nil {
+		c.Stop()
+		return nil, fmt.Errorf("failed to flush latency subscriptions: %w", err)
+	}
+	return c, nil
+}
+
+func (c *LatencyCollector) record(msg *nats.Msg) {
+	var sl server.ServiceLatency
+	if err := json.Unmarshal(msg.Data, &sl); err != nil {
+		log.Printf("Ignoring malformed latency sample on %s: %v", msg.Subject, err)
+		return
+	}
+	importer := "unknown"
+	if sl.Requestor != nil && sl.Requestor.Account != "" {
+		importer = sl.Requestor.Account
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := latencyKey{msg.Sub.Subject, importer}
+	c.samples[key] = append(c.samples[key], sl)
+}
+
+// Stop unsubscribes from the results subjects.
+func (c *LatencyCollector) Stop() {
+	for _, sub := range c.subs {
+		sub.Unsubscribe()
+	}
+}
+
+// Report returns the stats for each results subject and importer seen so
+// far, sorted by subject and importer.
+func (c *LatencyCollector) Report() []LatencyStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var report []LatencyStats
+	for key, samples := range c.samples {
+		stats := LatencyStats{Results: key.results, Importer: key.importer, Samples: len(samples)}
+		var total, service []time.Duration
+		for _, sl := range samples {
+			if sl.Status != 200 {
+				stats.Errors++
+				continue
+			}
+			total = append(total, sl.TotalLatency)
+			service = append(service, sl.ServiceLatency)
+		}
+		sort.Slice(total, func(i, j int) bool { return total[i] < total[j] })
+		sort.Slice(service, func(i, j int) bool { return service[i] < service[j] })
+		stats.P50 = percentile(total, 50)
+		stats.P90 = percentile(total, 90)
+		stats.P99 = percentile(total, 99)
+		stats.ServiceP50 = percentile(service, 50)
+		if len(total) > 0 {
+			stats.Max = total[len(total)-1]
+		}
+		report = append(report, stats)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Results != report[j].Results {
+			return report[i].Results < report[j].Results
+		}
+		return report[i].Importer < report[j].Importer
+	})
+	return report
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// PrintLatencyReport prints one line per service and importer.
+func PrintLatencyReport(report []LatencyStats) {
+	fmt.Printf("\n  %-14s %-9s %-8s %-7s %-10s %-10s %-10s %-10s %s\n",
+		"RESULTS", "IMPORTER", "SAMPLES", "ERRORS", "P50", "P90", "P99", "MAX", "SERVICE P50")
+	for _, s := range report {
+		fmt.Printf("  %-14s %-9s %-8d %-7d %-10s %-10s %-10s %-10s %s\n",
+			s.Results, s.Importer, s.Samples, s.Errors,
+			s.P50.Round(10*time.Microsecond), s.P90.Round(10*time.Microsecond),
+			s.P99.Round(10*time.Microsecond), s.Max.Round(10*time.Microsecond),
+			s.ServiceP50.Round(10*time.Microsecond))
+	}
+}
+
+// latencyDemoConfig mirrors the exports and imports of config/accounts.conf,
+// with latency tracking on A's services. B also imports pubq.B as Q, so
+// pubq.> has two importers.
+func latencyDemoConfig() *ServerConfig {
+	return &ServerConfig{
+		Header: []string{"Cross-account service latency tracking, generated by DemoServiceLatency"},
+		Port:   server.RANDOM_PORT,
+		Accounts: []ConfigAccount{
+			{
+				Name:  "A",
+				Users: []ConfigUser{{User: "user_a", Password: "pass_a"}},
+				Exports: []ConfigExport{
+					{Service: "pubq.>", Latency: &ConfigLatency{Results: "latency.pubq"}},
+					{Service: "q.b", Accounts: []string{"B"}, Latency: &ConfigLatency{Sampling: 50, Results: "latency.qb"}},
+				},
+			},
+			{
+				Name:  "B",
+				Users: []ConfigUser{{User: "user_b", Password: "pass_b"}},
+				Imports: []ConfigImport{
+					{Account: "A", Service: "q.b"},
+					{Account: "A", Service: "pubq.B", To: "Q"},
+				},
+			},
+			{
+				Name:  "C",
+				Users: []ConfigUser{{User: "user_c", Password: "pass_c"}},
+				Imports: []ConfigImport{
+					{Account: "A", Service: "pubq.C", To: "Q"},
+				},
+			},
+		},
+	}
+}
+
+// DemoServiceLatency demonstrates latency tracking on cross-account
+// service exports, with a collector reporting percentiles per importer
+func DemoServiceLatency() {
+	fmt.Println("\n=== Cross-Account Service Latency Demo ===")
+
+	dir, err := os.MkdirTemp("", "nats-latency-")
+	if err != nil {
+		log.Printf("Failed to create config dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := latencyDemoConfig()
+	configFile := filepath.Join(dir, "latency.conf")
+	if err := cfg.WriteFile(configFile); err != nil {
+		log.Printf("Failed to write config: %v", err)
+		return
+	}
+	rendered, _ := cfg.Render()
+	fmt.Printf("\n1. Generated config:\n\n%s\n", rendered)
+
+	opts, err := server.ProcessConfigFile(configFile)
+	if err != nil {
+		log.Printf("Failed to load generated config: %v", err)
+		return
+	}
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+
+	connA, err := nats.Connect(url, userPassword("user_a", "pass_a"))
+	if err != nil {
+		log.Printf("Account A connection failed: %v", err)
+		return
+	}
+	defer connA.Close()
+	connB, err := nats.Connect(url, userPassword("user_b", "pass_b"))
+	if err != nil {
+		log.Printf("Account B connection failed: %v", err)
+		return
+	}
+	defer connB.Close()
+	connC, err := nats.Connect(url, userPassword("user_c", "pass_c"))
+	if err != nil {
+		log.Printf("Account C connection failed: %v", err)
+		return
+	}
+	defer connC.Close()
+
+	collector, err := NewLatencyCollector(connA, "latency.pubq", "latency.qb")
+	if err != nil {
+		log.Printf("Latency collector failed: %v", err)
+		return
+	}
+	defer collector.Stop()
+
+	// Responders in A take 1-5ms for pubq.* and 5-15ms for q.b.
+	work := func(min, spread int) func(*nats.Msg) {
+		return func(msg *nats.Msg) {
+			time.Sleep(time.Duration(min+rand.Intn(spread)) * time.Millisecond)
+			msg.Respond([]byte("ok from A"))
+		}
+	}
+	connA.Subscribe("pubq.*", work(1, 5))
+	connA.Subscribe("q.b", work(5, 10))
+	connA.Flush()
+	fmt.Println("\n2. Account A serving pubq.* (1-5ms) and q.b (5-15ms)")
+
+	const requests = 40
+	fmt.Printf("\n3. Sending %d requests per importer:\n", requests)
+	calls := []struct {
+		label   string
+		nc      *nats.Conn
+		subject string
+	}{
+		{"C: Q -> pubq.C", connC, "Q"},
+		{"B: Q -> pubq.B", connB, "Q"},
+		{"B: q.b", connB, "q.b"},
+	}
+	for _, call := range calls {
+		failed := 0
+		for i := 0; i < requests; i++ {
+			if _, err := call.nc.Request(call.subject, []byte("ping"), 2*time.Second); err != nil {
+				failed++
+			}
+		}
+		if failed == 0 {
+			fmt.Printf("✓ %s: %d responses\n", call.label, requests)
+		} else {
+			fmt.Printf("❌ %s: %d of %d requests failed\n", call.label, failed, requests)
+		}
+	}
+
+	// The server records a request without a reply subject as a 400.
+	connC.Publish("Q", []byte("no reply"))
+	connC.Flush()
+	fmt.Println("✓ C: published to Q without a reply subject (recorded as an error sample)")
+
+	// Samples are published asynchronously after each response.
+	time.Sleep(500 * time.Millisecond)
+
+	fmt.Println("\n4. Latency per service and importer (q.b samples 50% of requests):")
+	PrintLatencyReport(collector.Report())
+
+	fmt.Println("\n=== Cross-Account Service Latency Demo Complete ===")
+}
@@ -0,0 +1,254 @@
+package examples
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// Reasons RequestStream stops collecting.
+const (
+	StreamEndSentinel = "sentinel"
+	StreamEndIdle     = "idle"
+	StreamEndMax      = "max"
+	StreamEndTimeout  = "timeout"
+)
+
+// StreamOptions control RequestStream. Zero values pick the defaults.
+type StreamOptions struct {
+	// Sentinel reports whether a response ends the stream. The default is
+	// an empty message, which is also what makes the server drop the
+	// reply mapping of a stream or chunked service import.
+	Sentinel func(msg *nats.Msg) bool
+	// Idle is how long to wait for the next response; default 1s.
+	Idle time.Duration
+	// Max stops after this many responses; zero means no limit.
+	Max int
+	// Timeout bounds the whole request; zero means no limit.
+	Timeout time.Duration
+}
+
+// StreamResult holds the responses to one request, without the sentinel.
+type StreamResult struct {
+	Responses []*nats.Msg
+	// End is why collection stopped, one of the StreamEnd reasons.
+	End     string
+	Elapsed time.Duration
+}
+
+// Data joins the payloads of the responses, reassembling chunks.
+func (r *StreamResult) Data() []byte {
+	var b bytes.Buffer
+	for _, msg := range r.Responses {
+		b.Write(msg.Data)
+	}
+	return b.Bytes()
+}
+
+// IsEmptyMessage is the default sentinel.
+func IsEmptyMessage(msg *nats.Msg) bool {
+	return len(msg.Data) == 0
+}
+
+// RequestStream sends a request and collects responses on a private inbox
+// until the sentinel arrives, no response arrives for opts.Idle, opts.Max
+// responses arrived or opts.Timeout passed.
+func RequestStream(nc *nats.Conn, subject string, data []byte, opts StreamOptions) (*StreamResult, error) {
+	if opts.Sentinel == nil {
+		opts.Sentinel = IsEmptyMessage
+	}
+	if opts.Idle <= 0 {
+		opts.Idle = time.Second
+	}
+
+	inbox := nc.NewRespInbox()
+	sub, err := nc.SubscribeSync(inbox)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", inbox, err)
+	}
+	defer sub.Unsubscribe()
+	if err := nc.PublishRequest(subject, inbox, data); err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", subject, err)
+	}
+
+	start := time.Now()
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = start.Add(opts.Timeout)
+	}
+	result := &StreamResult{}
+	defer func() { result.Elapsed = time.Since(start) }()
+	for {
+		wait, end := opts.Idle, StreamEndIdle
+		if !deadline.IsZero() && time.Until(deadline) < wait {
+			wait, end = time.Until(deadline), StreamEndTimeout
+		}
+		msg, err := sub.NextMsg(wait)
+		switch {
+		case err == nats.ErrTimeout:
+			result.End = end
+			return result, nil
+		case err != nil:
+			return result, fmt.Errorf("failed to receive response: %w", err)
+		case opts.Sentinel(msg):
+			result.End = StreamEndSentinel
+			return result, nil
+		}
+		result.Responses = append(result.Responses, msg)
+		if opts.Max > 0 && len(result.Responses) >= opts.Max {
+			result.End = StreamEndMax
+			return result, nil
+		}
+	}
+}
+
+// streamingDemoConfig has account A export the same price feed as a
+// singleton and a stream service, and a file download as a chunked
+// service; B imports all three.
+func streamingDemoConfig() *ServerConfig {
+	return &ServerConfig{
+		Header: []string{"Streamed and chunked service exports, generated by DemoStreamedResponses"},
+		Port:   server.RANDOM_PORT,
+		Accounts: []ConfigAccount{
+			{
+				Name:  "A",
+				Users: []ConfigUser{{User: "user_a", Password: "pass_a"}},
+				Exports: []ConfigExport{
+					{Service: "prices.single"},
+					{Service: "prices.stream", ResponseType: ResponseStream, ResponseThreshold: 5 * time.Second},
+					{Service: "files.get", ResponseType: ResponseChunked, ResponseThreshold: 5 * time.Second},
+				},
+			},
+			{
+				Name:  "B",
+				Users: []ConfigUser{{User: "user_b", Password: "pass_b"}},
+				Imports: []ConfigImport{
+					{Account: "A", Service: "prices.single"},
+					{Account: "A", Service: "prices.stream"},
+					{Account: "A", Service: "files.get"},
+				},
+			},
+		},
+	}
+}
+
+// DemoStreamedResponses demonstrates cross-account services that answer
+// one request with many messages, compared with a singleton export
+func DemoStreamedResponses() {
+	fmt.Println("\n=== Streamed and Chunked Service Responses Demo ===")
+
+	dir, err := os.MkdirTemp("", "nats-streaming-")
+	if err != nil {
+		log.Printf("Failed to create config dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := streamingDemoConfig()
+	configFile := filepath.Join(dir, "streaming.conf")
+	if err := cfg.WriteFile(configFile); err != nil {
+		log.Printf("Failed to write config: %v", err)
+		return
+	}
+	rendered, _ := cfg.Render()
+	fmt.Printf("\n1. Generated config:\n\n%s\n", rendered)
+
+	opts, err := server.ProcessConfigFile(configFile)
+	if err != nil {
+		log.Printf("Failed to load generated config: %v", err)
+		return
+	}
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+
+	connA, err := nats.Connect(url, userPassword("user_a", "pass_a"))
+	if err != nil {
+		log.Printf("Account A connection failed: %v", err)
+		return
+	}
+	defer connA.Close()
+	connB, err := nats.Connect(url, userPassword("user_b", "pass_b"))
+	if err != nil {
+		log.Printf("Account B connection failed: %v", err)
+		return
+	}
+	defer connB.Close()
+
+	// Both price services run the same handler: five updates, then the
+	// empty sentinel.
+	prices := func(msg *nats.Msg) {
+		for i := 1; i <= 5; i++ {
+			connA.Publish(msg.Reply, []byte(fmt.Sprintf("price update %d: %.2f", i, 100+float64(i)*0.25)))
+			time.Sleep(20 * time.Millisecond)
+		}
+		connA.Publish(msg.Reply, nil)
+	}
+	connA.Subscribe("prices.*", prices)
+
+	file := []byte(strings.Repeat("NATS service exports can stream responses across accounts. ", 40))
+	const chunkSize = 256
+	connA.Subscribe("files.get", func(msg *nats.Msg) {
+		for off := 0; off < len(file); off += chunkSize {
+			end := off + chunkSize
+			if end > len(file) {
+				end = len(file)
+			}
+			connA.Publish(msg.Reply, file[off:end])
+		}
+		connA.Publish(msg.Reply, nil)
+	})
+	connA.Flush()
+	fmt.Println("\n2. Account A answers prices.* with 5 updates and files.get with 256-byte chunks, each ending with an empty message")
+
+	request := func(subject string) *StreamResult {
+		result, err := RequestStream(connB, subject, []byte("request from B"), StreamOptions{Idle: 500 * time.Millisecond, Timeout: 5 * time.Second})
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", subject, err)
+			return nil
+		}
+		return result
+	}
+
+	fmt.Println("\n3. B requests prices.single (singleton export):")
+	if r := request("prices.single"); r != nil {
+		for _, msg := range r.Responses {
+			fmt.Printf("  ✓ %s\n", msg.Data)
+		}
+		fmt.Printf("✗ Only %d of 5 updates crossed into B; stopped on %s after %s\n",
+			len(r.Responses), r.End, r.Elapsed.Round(100*time.Microsecond))
+	}
+
+	fmt.Println("\n4. B requests prices.stream (stream export):")
+	if r := request("prices.stream"); r != nil {
+		for _, msg := range r.Responses {
+			fmt.Printf("  ✓ %s\n", msg.Data)
+		}
+		fmt.Printf("✓ %d updates, stopped on %s after %s\n", len(r.Responses), r.End, r.Elapsed.Round(100*time.Microsecond))
+	}
+
+	fmt.Println("\n5. B requests files.get (chunked export):")
+	if r := request("files.get"); r != nil {
+		data := r.Data()
+		fmt.Printf("✓ %d chunks, %d bytes, stopped on %s after %s\n", len(r.Responses), len(data), r.End, r.Elapsed.Round(100*time.Microsecond))
+		if sha256.Sum256(data) == sha256.Sum256(file) {
+			fmt.Println("✓ Reassembled file matches the original")
+		} else {
+			fmt.Println("❌ Reassembled file differs from the original")
+		}
+	}
+
+	fmt.Println("\n=== Streamed and Chunked Service Responses Demo Complete ===")
+}
@@ -0,0 +1,533 @@
+package examples
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+// STSSubject is where workloads ask for short-lived credentials.
+const STSSubject = "sts.credentials.issue"
+
+// stsMaxClockSkew is how old a signed request may be before it is refused
+// as a possible replay.
+const stsMaxClockSkew = 30 * time.Second
+
+// STSGrant lists the subjects a workload may request credentials for.
+type STSGrant struct {
+	Publish   []string `json:"publish"`
+	Subscribe []string `json:"subscribe"`
+}
+
+// STSPolicy decides which workloads may obtain credentials, for which
+// subjects and for how long.
+type STSPolicy struct {
+	MaxTTL     JSONDuration        `json:"max_ttl"`
+	DefaultTTL JSONDuration        `json:"default_ttl"`
+	Workloads  map[string]STSGrant `json:"workloads"`
+}
+
+// JSONDuration is a time.Duration that reads and writes as "5m" in JSON.
+type JSONDuration time.Duration
+
+func (d JSONDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *JSONDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = JSONDuration(v)
+	return nil
+}
+
+// LoadSTSPolicy reads a policy from a JSON file.
+func LoadSTSPolicy(filename string) (*STSPolicy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read STS policy: %w", err)
+	}
+
+	var p STSPolicy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse STS policy: %w", err)
+	}
+	return &p, nil
+}
+
+// Check returns the TTL to issue if the workload may have credentials for
+// the requested subjects.
+func (p *STSPolicy) Check(workload string, req *STSRequest) (time.Duration, error) {
+	grant, ok := p.Workloads[workload]
+	if !ok {
+		return 0, fmt.Errorf("workload %q is not allowed to request credentials", workload)
+	}
+	if len(req.Publish) == 0 && len(req.Subscribe) == 0 {
+		return 0, fmt.Errorf("no subjects requested")
+	}
+	for _, subject := range req.Publish {
+		if !subjectCoveredBy(subject, grant.Publish) {
+			return 0, fmt.Errorf("publish to %q is outside the grant for %q", subject, workload)
+		}
+	}
+	for _, subject := range req.Subscribe {
+		if !subjectCoveredBy(subject, grant.Subscribe) {
+			return 0, fmt.Errorf("subscribe to %q is outside the grant for %q", subject, workload)
+		}
+	}
+
+	ttl := time.Duration(p.DefaultTTL)
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			return 0, fmt.Errorf("invalid ttl %q", req.TTL)
+		}
+	}
+	if ttl > time.Duration(p.MaxTTL) {
+		return 0, fmt.Errorf("ttl %s exceeds the %s maximum", ttl, time.Duration(p.MaxTTL))
+	}
+	return ttl, nil
+}
+
+// subjectCoveredBy reports whether every subject matching subject also
+// matches one of patterns.
+func subjectCoveredBy(subject string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if subjectCovers(pattern, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+func subjectCovers(pattern, subject string) bool {
+	pt := strings.Split(pattern, ".")
+	st := strings.Split(subject, ".")
+	for i, p := range pt {
+		if p == ">" {
+			return len(st) > i
+		}
+		if i >= len(st) {
+			return false
+		}
+		switch {
+		case st[i] == ">":
+			return false
+		case p == "*":
+		case p != st[i]:
+			return false
+		}
+	}
+	return len(st) == len(pt)
+}
+
+// STSRequest asks for short-lived credentials. It carries the workload's
+// own user JWT and is signed with the workload's nkey, proving the caller
+// holds the seed behind that JWT.
+type STSRequest struct {
+	JWT       string   `json:"jwt"`
+	Publish   []string `json:"publish,omitempty"`
+	Subscribe []string `json:"subscribe,omitempty"`
+	TTL       string   `json:"ttl,omitempty"`
+	IssuedAt  int64    `json:"iat"`
+	Signature string   `json:"sig,omitempty"`
+}
+
+// signingBytes is the request as signed: everything but the signature.
+func (r STSRequest) signingBytes() ([]byte, error) {
+	r.Signature = ""
+	return json.Marshal(r)
+}
+
+// STSResponse carries the issued credentials or the reason for refusing.
+type STSResponse struct {
+	JWT     string    `json:"jwt,omitempty"`
+	Seed    string    `json:"seed,omitempty"`
+	Creds   string    `json:"creds,omitempty"`
+	Expires time.Time `json:"expires,omitempty"`
+	// InboxPrefix is the only inbox the credentials may subscribe to;
+	// connect with nats.CustomInboxPrefix(InboxPrefix) to use replies.
+	InboxPrefix string `json:"inbox_prefix,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// STSService issues short-lived user JWTs in one account on behalf of
+// workloads already authenticated in that account.
+type STSService struct {
+	Operator *JWTOperator
+	Account  string
+	Policy   *STSPolicy
+
+	// Decisions, if set, is called with every issue or refusal.
+	Decisions func(workload string, err error)
+}
+
+// Start subscribes the service on a connection in the service's account.
+func (s *STSService) Start(nc *nats.Conn) (*nats.Subscription, error) {
+	sub, err := nc.Subscribe(STSSubject, s.handle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", STSSubject, err)
+	}
+	return sub, nil
+}
+
+func (s *STSService) handle(msg *nats.Msg) {
+	var resp STSResponse
+
+	workload, user, inbox, err := s.issue(msg.Data)
+	if err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.JWT = user.JWT
+		resp.Seed = user.Seed
+		resp.Expires = user.Expires
+		resp.InboxPrefix = inbox
+		creds, cerr := user.Creds()
+		if cerr != nil {
+			resp = STSResponse{Error: cerr.Error()}
+		} else {
+			resp.Creds = string(creds)
+		}
+	}
+	if s.Decisions != nil {
+		s.Decisions(workload, err)
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("STS: failed to encode response: %v", err)
+		return
+	}
+	if err := msg.Respond(data); err != nil {
+		log.Printf("STS: failed to respond: %v", err)
+	}
+}
+
+// issue verifies a request and returns the workload name, the issued
+// credentials and the inbox prefix they may subscribe to.
+func (s *STSService) issue(data []byte) (string, *IssuedUser, string, error) {
+	var req STSRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return "", nil, "", fmt.Errorf("bad request: %w", err)
+	}
+
+	acc, err := s.Operator.Account(s.Account)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	uc, err := jwt.DecodeUserClaims(req.JWT)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("bad workload jwt: %w", err)
+	}
+	workload := uc.Name
+	// DecodeUserClaims only checks the signature against the key the JWT
+	// names, so the issuer must be the account key or one of its signing
+	// keys; issuer_account alone is claimed by whoever signed.
+	if uc.Issuer != acc.PublicKey &&
+		(uc.IssuerAccount != acc.PublicKey || !acc.Claims.SigningKeys.Contains(uc.Issuer)) {
+		return workload, nil, "", fmt.Errorf("workload jwt was not issued by account %s", s.Account)
+	}
+	var vr jwt.ValidationResults
+	uc.Validate(&vr)
+	if vr.IsBlocking(true) {
+		return workload, nil, "", fmt.Errorf("workload jwt is not valid: %v", vr.Errors())
+	}
+	if acc.Claims.IsClaimRevoked(uc) {
+		return workload, nil, "", fmt.Errorf("workload %s has been revoked", workload)
+	}
+
+	if err := verifySTSRequest(&req, uc.Subject); err != nil {
+		return workload, nil, "", err
+	}
+
+	ttl, err := s.Policy.Check(workload, &req)
+	if err != nil {
+		return workload, nil, "", err
+	}
+
+	// Each credential gets its own inbox, so it cannot read the replies
+	// sent to other clients.
+	inbox := nats.NewInbox()
+	user, err := s.Operator.IssueUser(s.Account, UserSpec{
+		Name:        workload + "-sts",
+		Permissions: PermissionsFor(req.Publish, append(req.Subscribe, inbox+".>")),
+		ExpiresIn:   ttl,
+	})
+	if err != nil {
+		return workload, nil, "", err
+	}
+	return workload, user, inbox, nil
+}
+
+// verifySTSRequest checks the request signature against the workload's
+// public key and refuses stale requests.
+func verifySTSRequest(req *STSRequest, publicKey string) error {
+	if age := time.Since(time.Unix(req.IssuedAt, 0)); age > stsMaxClockSkew || age < -stsMaxClockSkew {
+		return fmt.Errorf("request timestamp is outside the %s window", stsMaxClockSkew)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(req.Signature)
+	if err != nil {
+		return fmt.Errorf("bad request signature: %w", err)
+	}
+	body, err := req.signingBytes()
+	if err != nil {
+		return err
+	}
+
+	kp, err := nkeys.FromPublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("bad workload public key: %w", err)
+	}
+	if err := kp.Verify(body, sig); err != nil {
+		return fmt.Errorf("request is not signed by the workload's key")
+	}
+	return nil
+}
+
+// RequestSTSCredentials signs req with the workload's seed and asks the STS
+// for credentials.
+func RequestSTSCredentials(nc *nats.Conn, workloadJWT, workloadSeed string, req STSRequest) (*STSResponse, error) {
+	kp, err := nkeys.FromSeed([]byte(workloadSeed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse workload seed: %w", err)
+	}
+
+	req.JWT = workloadJWT
+	req.IssuedAt = time.Now().Unix()
+	body, err := req.signingBytes()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := kp.Sign(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+	req.Signature = base64.RawURLEncoding.EncodeToString(sig)
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	msg, err := nc.Request(STSSubject, data, 2*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("STS request failed: %w", err)
+	}
+
+	var resp STSResponse
+	if err := json.Unmarshal(msg.Data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse STS response: %w", err)
+	}
+	if resp.Error != "" {
+		return &resp, fmt.Errorf("STS refused: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// forgeWorkloadJWT signs a billing user JWT with a fresh account key and
+// names account as its issuer_account, as an attacker without the
+// account's keys could.
+func forgeWorkloadJWT(op *JWTOperator, account string) (string, string, error) {
+	acc, err := op.Account(account)
+	if err != nil {
+		return "", "", err
+	}
+	foreign, err := nkeys.CreateAccount()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create account key: %w", err)
+	}
+	user, err := nkeys.CreateUser()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create user key: %w", err)
+	}
+	pub, _ := user.PublicKey()
+	seed, _ := user.Seed()
+	uc := jwt.NewUserClaims(pub)
+	uc.Name = "billing"
+	uc.IssuerAccount = acc.PublicKey
+	token, err := uc.Encode(foreign)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign forged jwt: %w", err)
+	}
+	return token, string(seed), nil
+}
+
+// DemoSTSCredentials shows a workload trading its long-lived identity for
+// narrowly scoped credentials that expire mid-session.
+func DemoSTSCredentials() {
+	fmt.Println("\n=== Short-Lived Credentials (STS) Demo ===")
+
+	resolverDir, err := os.MkdirTemp("", "nats-jwt-resolver-")
+	if err != nil {
+		log.Printf("Failed to create resolver dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(resolverDir)
+
+	fmt.Println("\n1. Creating operator, account APP and the STS service...")
+	op, err := NewJWTOperator("demo")
+	if err != nil {
+		log.Printf("Operator setup failed: %v", err)
+		return
+	}
+	if _, err := op.AddAccount("APP"); err != nil {
+		log.Printf("Account setup failed: %v", err)
+		return
+	}
+
+	opts, err := op.ServerOptions(resolverDir)
+	if err != nil {
+		log.Printf("Server options failed: %v", err)
+		return
+	}
+	srv, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Embedded server failed: %v", err)
+		return
+	}
+	defer srv.Shutdown()
+	url := srv.ClientURL()
+
+	policy, err := LoadSTSPolicy("config/sts-policy.json")
+	if err != nil {
+		log.Printf("Failed to load policy: %v", err)
+		return
+	}
+	fmt.Printf("  ✓ Policy from config/sts-policy.json (max ttl %s)\n", time.Duration(policy.MaxTTL))
+
+	stsUser, err := op.IssueUser("APP", UserSpec{
+		Name:        "sts",
+		Permissions: PermissionsFor([]string{"_INBOX.>"}, []string{STSSubject}),
+	})
+	if err != nil {
+		log.Printf("Issue STS user failed: %v", err)
+		return
+	}
+	stsConn, err := nats.Connect(url, stsUser.ConnectOption(), nats.Name("sts"))
+	if err != nil {
+		log.Printf("STS connection failed: %v", err)
+		return
+	}
+	defer stsConn.Close()
+
+	sts := &STSService{
+		Operator: op,
+		Account:  "APP",
+		Policy:   policy,
+		Decisions: func(workload string, err error) {
+			if err != nil {
+				fmt.Printf("  [sts] ✗ refused %s: %v\n", workload, err)
+			} else {
+				fmt.Printf("  [sts] ✓ issued credentials to %s\n", workload)
+			}
+		},
+	}
+	if _, err := sts.Start(stsConn); err != nil {
+		log.Printf("Failed to start STS: %v", err)
+		return
+	}
+	stsConn.Flush()
+	fmt.Printf("  ✓ STS listening on %s\n", STSSubject)
+
+	fmt.Println("\n2. The billing workload connects with its long-lived identity:")
+	billing, err := op.IssueUser("APP", UserSpec{
+		Name:        "billing",
+		Permissions: PermissionsFor([]string{STSSubject}, []string{"_INBOX.>"}),
+	})
+	if err != nil {
+		log.Printf("Issue billing failed: %v", err)
+		return
+	}
+	billingConn, err := nats.Connect(url, billing.ConnectOption(), nats.Name("billing"))
+	if err != nil {
+		log.Printf("Billing connection failed: %v", err)
+		return
+	}
+	defer billingConn.Close()
+	fmt.Println("  ✓ billing can only talk to the STS")
+
+	fmt.Println("\n3. Requesting credentials for app.billing.invoices (ttl 3s):")
+	resp, err := RequestSTSCredentials(billingConn, billing.JWT, billing.Seed, STSRequest{
+		Publish: []string{"app.billing.invoices"},
+		TTL:     "3s",
+	})
+	if err != nil {
+		log.Printf("STS request failed: %v", err)
+		return
+	}
+	fmt.Printf("  ✓ Received a user JWT, ephemeral seed and .creds (%d bytes), expires %s\n",
+		len(resp.Creds), describeExpiry(resp.Expires))
+	fmt.Printf("  ✓ Replies only on its own inbox %s.>\n", resp.InboxPrefix)
+
+	ephemeral := &IssuedUser{Name: "billing-sts", JWT: resp.JWT, Seed: resp.Seed, Expires: resp.Expires}
+	conn, err := connectWatched(url, ephemeral, nats.CustomInboxPrefix(resp.InboxPrefix))
+	if err != nil {
+		log.Printf("Ephemeral connection failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	if err := conn.Publish("app.billing.invoices", []byte("invoice #1")); err == nil && conn.Flush() == nil {
+		fmt.Println("  ✓ Published to 'app.billing.invoices' with the ephemeral credentials")
+	}
+	conn.Publish("app.payroll.run", []byte("nope"))
+	conn.Flush()
+	select {
+	case ev := <-conn.events:
+		fmt.Printf("  ✗ Correctly denied 'app.payroll.run': %s\n", ev)
+	case <-time.After(time.Second):
+		fmt.Println("  ❌ Publish to 'app.payroll.run' was not denied")
+	}
+	conn.SubscribeSync("_INBOX.>")
+	conn.Flush()
+	select {
+	case ev := <-conn.events:
+		fmt.Printf("  ✗ Correctly denied other clients' replies on '_INBOX.>': %s\n", ev)
+	case <-time.After(time.Second):
+		fmt.Println("  ❌ Subscribe to '_INBOX.>' was not denied")
+	}
+
+	fmt.Println("  Waiting for the credentials to expire mid-session...")
+	if conn.waitClosed(6 * time.Second) {
+		fmt.Println("  ✓ Server closed the session when the credentials expired")
+	} else {
+		fmt.Println("  ❌ Session still open after expiry")
+	}
+
+	fmt.Println("\n4. Requests the policy refuses:")
+	if _, err := RequestSTSCredentials(billingConn, billing.JWT, billing.Seed, STSRequest{Publish: []string{"app.payroll.>"}}); err != nil {
+		fmt.Printf("  ✗ Subject outside the grant: %v\n", err)
+	}
+	if _, err := RequestSTSCredentials(billingConn, billing.JWT, billing.Seed, STSRequest{Publish: []string{"app.billing.invoices"}, TTL: "1h"}); err != nil {
+		fmt.Printf("  ✗ TTL above the maximum: %v\n", err)
+	}
+	other, _ := nkeys.CreateUser()
+	otherSeed, _ := other.Seed()
+	if _, err := RequestSTSCredentials(billingConn, billing.JWT, string(otherSeed), STSRequest{Publish: []string{"app.billing.invoices"}}); err != nil {
+		fmt.Printf("  ✗ Stolen JWT without the seed: %v\n", err)
+	}
+	if forgedJWT, forgedSeed, err := forgeWorkloadJWT(op, "APP"); err != nil {
+		log.Printf("Failed to forge workload jwt: %v", err)
+	} else if _, err := RequestSTSCredentials(billingConn, forgedJWT, forgedSeed, STSRequest{Publish: []string{"app.billing.invoices"}}); err != nil {
+		fmt.Printf("  ✗ JWT signed by a foreign key claiming issuer_account APP: %v\n", err)
+	} else {
+		fmt.Println("  ❌ Forged workload JWT was accepted")
+	}
+
+	fmt.Println("\n=== STS Demo Complete ===")
+}
@@ -0,0 +1,275 @@
+package examples
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// MappingShare compares how many messages a mapped destination received
+// with its configured weight.
+type MappingShare struct {
+	Subject string
+	// Weight is the configured percentage.
+	Weight   int
+	Count    int
+	Observed float64
+}
+
+// WeightedShares reports the share of each destination of m in counts,
+// which holds the messages received per subject. Weights totalling less
+// than 100 add the original subject with the remainder.
+func WeightedShares(m ConfigMapping, counts map[string]int) []MappingShare {
+	var shares []MappingShare
+	total, weights := 0, 0
+	for _, d := range m.Destinations {
+		shares = append(shares, MappingShare{Subject: d.Subject, Weight: d.Weight, Count: counts[d.Subject]})
+		weights += d.Weight
+	}
+	if weights < 100 {
+		shares = append(shares, MappingShare{Subject: m.Subject, Weight: 100 - weights, Count: counts[m.Subject]})
+	}
+	for _, s := range shares {
+		total += s.Count
+	}
+	for i := range shares {
+		if total > 0 {
+			shares[i].Observed = 100 * float64(shares[i].Count) / float64(total)
+		}
+	}
+	return shares
+}
+
+// collectSubjects counts the messages arriving on sub by subject until
+// want messages arrived or nothing arrives for idle.
+func collectSubjects(sub *nats.Subscription, want int, idle time.Duration) map[string]int {
+	counts := map[string]int{}
+	for n := 0; n < want; n++ {
+		msg, err := sub.NextMsg(idle)
+		if err != nil {
+			break
+		}
+		counts[msg.Subject]++
+	}
+	return counts
+}
+
+// mappingsDemoConfig has one account whose mappings rearrange order
+// subjects, split api.req between two versions, and partition events by
+// key. The server checks publish permissions after mapping, so app is
+// allowed the destinations, legacy only the original subjects and canary
+// only api.v2.
+func mappingsDemoConfig() *ServerConfig {
+	return &ServerConfig{
+		Header: []string{"Subject mappings, generated by DemoSubjectMappings"},
+		Port:   server.RANDOM_PORT,
+		Accounts: []ConfigAccount{
+			{
+				Name: "APP",
+				Users: []ConfigUser{
+					{User: "app", Password: "app123", Permissions: &ConfigPermissions{
+						Publish:   []string{"byregion.>", "api.v1", "api.v2", "part.>"},
+						Subscribe: []string{"_INBOX.>"},
+					}},
+					{User: "legacy", Password: "legacy123", Permissions: &ConfigPermissions{
+						Publish:   []string{"orders.>", "api.req", "events.>"},
+						Subscribe: []string{"_INBOX.>"},
+					}},
+					{User: "canary", Password: "canary123", Permissions: &ConfigPermissions{
+						Publish:   []string{"api.v2"},
+						Subscribe: []string{"_INBOX.>"},
+					}},
+					{User: "observer", Password: "observer123", Permissions: &ConfigPermissions{
+						PublishDeny: []string{">"},
+						Subscribe:   []string{">"},
+					}},
+				},
+				Mappings: []ConfigMapping{
+					MapTo("orders.*.*", "byregion."+MappingWildcard(2)+"."+MappingWildcard(1)),
+					{Subject: "api.req", Destinations: []ConfigMappingDestination{
+						{Subject: "api.v1", Weight: 90},
+						{Subject: "api.v2", Weight: 10},
+					}},
+					MapTo("events.*", "part."+MappingPartition(4, 1)+"."+MappingWildcard(1)),
+				},
+			},
+		},
+	}
+}
+
+// DemoSubjectMappings demonstrates account subject mappings: wildcard
+// token rearrangement, a weighted canary split and deterministic
+// partitioning, and how they interact with publish permissions
+func DemoSubjectMappings() {
+	fmt.Println("\n=== Subject Mappings and Weighted Transforms Demo ===")
+
+	dir, err := os.MkdirTemp("", "nats-mappings-")
+	if err != nil {
+		log.Printf("Failed to create config dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := mappingsDemoConfig()
+	configFile := filepath.Join(dir, "mappings.conf")
+	if err := cfg.WriteFile(configFile); err != nil {
+		log.Printf("Failed to write config: %v", err)
+		return
+	}
+	rendered, _ := cfg.Render()
+	fmt.Printf("\n1. Generated config:\n\n%s\n", rendered)
+
+	opts, err := server.ProcessConfigFile(configFile)
+	if err != nil {
+		log.Printf("Failed to load generated config: %v", err)
+		return
+	}
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+
+	connect := func(user, password string) *nats.Conn {
+		nc, err := nats.Connect(url, userPassword(user, password))
+		if err != nil {
+			log.Printf("%s connection failed: %v", user, err)
+			return nil
+		}
+		return nc
+	}
+	app, legacy, canary, observer := connect("app", "app123"), connect("legacy", "legacy123"),
+		connect("canary", "canary123"), connect("observer", "observer123")
+	for _, nc := range []*nats.Conn{app, legacy, canary, observer} {
+		if nc == nil {
+			return
+		}
+		defer nc.Close()
+	}
+	legacyErrs := asyncErrors(legacy)
+	canaryErrs := asyncErrors(canary)
+
+	// observer sees every message in the account, after mapping.
+	all, err := observer.SubscribeSync(">")
+	if err != nil {
+		log.Printf("Observer subscribe failed: %v", err)
+		return
+	}
+	all.SetPendingLimits(-1, -1)
+	observer.Flush()
+
+	fmt.Println("\n2. Wildcard token rearrangement (orders.<id>.<region> -> byregion.<region>.<id>):")
+	for _, subject := range []string{"orders.1001.eu", "orders.1002.us", "orders.1003.eu"} {
+		app.Publish(subject, []byte("order"))
+		app.Flush()
+		if msg, err := all.NextMsg(time.Second); err == nil {
+			fmt.Printf("✓ %s arrived as %s\n", subject, msg.Subject)
+		} else {
+			fmt.Printf("❌ %s did not arrive: %v\n", subject, err)
+		}
+	}
+
+	const requests = 2000
+	split := cfg.Accounts[0].Mappings[1]
+	fmt.Printf("\n3. Weighted canary split of %d messages on api.req:\n", requests)
+	for i := 0; i < requests; i++ {
+		app.Publish("api.req", []byte("request"))
+	}
+	app.Flush()
+	counts := collectSubjects(all, requests, time.Second)
+	fmt.Printf("\n  %-10s %-10s %-8s %s\n", "SUBJECT", "WEIGHT", "COUNT", "OBSERVED")
+	for _, share := range WeightedShares(split, counts) {
+		mark := "✓"
+		if d := share.Observed - float64(share.Weight); d > 3 || d < -3 {
+			mark = "❌"
+		}
+		fmt.Printf("%s %-10s %-10s %-8d %.1f%%\n", mark, share.Subject, fmt.Sprintf("%d%%", share.Weight), share.Count, share.Observed)
+	}
+
+	const keys, rounds = 40, 25
+	fmt.Printf("\n4. Partitioning events.<key> into 4 partitions (%d keys x %d messages):\n", keys, rounds)
+	for r := 0; r < rounds; r++ {
+		for k := 0; k < keys; k++ {
+			app.Publish(fmt.Sprintf("events.user-%d", k), []byte("event"))
+		}
+	}
+	app.Flush()
+	partitions := map[string]map[string]bool{}
+	perPartition := map[string]int{}
+	for subject, n := range collectSubjects(all, keys*rounds, time.Second) {
+		// part.<partition>.<key>
+		tokens := strings.SplitN(subject, ".", 3)
+		if len(tokens) != 3 {
+			continue
+		}
+		if partitions[tokens[2]] == nil {
+			partitions[tokens[2]] = map[string]bool{}
+		}
+		partitions[tokens[2]][tokens[1]] = true
+		perPartition[tokens[1]] += n
+	}
+	stable := 0
+	for _, seen := range partitions {
+		if len(seen) == 1 {
+			stable++
+		}
+	}
+	var names []string
+	for p := range perPartition {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+	for _, p := range names {
+		fmt.Printf("  partition %s: %d messages\n", p, perPartition[p])
+	}
+	for k := 0; k < 4; k++ {
+		key := fmt.Sprintf("user-%d", k)
+		for p := range partitions[key] {
+			fmt.Printf("  %s -> partition %s\n", key, p)
+		}
+	}
+	if stable == keys {
+		fmt.Printf("✓ All %d keys always landed in the same partition\n", keys)
+	} else {
+		fmt.Printf("❌ Only %d of %d keys always landed in the same partition\n", stable, keys)
+	}
+
+	fmt.Println("\n5. Publish permissions are checked against the mapped subject:")
+	legacy.Publish("orders.1004.eu", []byte("order"))
+	legacy.Flush()
+	if err := waitError(legacyErrs, 500*time.Millisecond); err != nil {
+		fmt.Printf("✗ legacy may publish orders.> but is denied its destination: %v\n", err)
+	} else {
+		fmt.Println("❌ legacy published orders.1004.eu")
+	}
+	const canaryRequests = 200
+	for i := 0; i < canaryRequests; i++ {
+		canary.Publish("api.req", []byte("request"))
+	}
+	canary.Flush()
+	counts = collectSubjects(all, canaryRequests, 500*time.Millisecond)
+	denied := drainErrors(canaryErrs, 200*time.Millisecond)
+	fmt.Printf("✓ canary may only publish api.v2: %d of %d api.req messages were mapped there and delivered\n",
+		counts["api.v2"], canaryRequests)
+	if len(denied) > 0 {
+		fmt.Printf("✗ The rest were mapped to api.v1 and denied: %v\n", denied[0])
+	}
+	app.Publish("api.v2", []byte("direct"))
+	app.Flush()
+	if msg, err := all.NextMsg(500 * time.Millisecond); err == nil && msg.Subject == "api.v2" {
+		fmt.Println("✓ app published api.v2 directly; it is not a mapped subject, so it arrives unchanged")
+	} else {
+		fmt.Println("❌ app's direct api.v2 message did not arrive")
+	}
+
+	fmt.Println("\n=== Subject Mappings and Weighted Transforms Demo Complete ===")
+}
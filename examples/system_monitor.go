@@ -0,0 +1,292 @@
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// Kinds of AuthEvent.
+const (
+	EventConnect    = "connect"
+	EventDisconnect = "disconnect"
+	EventAuthError  = "auth_error"
+)
+
+// System account subjects watched by SystemMonitor.
+const (
+	connectEventSubject    = "$SYS.ACCOUNT.*.CONNECT"
+	disconnectEventSubject = "$SYS.ACCOUNT.*.DISCONNECT"
+	authErrorEventSubject  = "$SYS.SERVER.*.CLIENT.AUTH.ERR"
+	connzRequestSubject    = "$SYS.REQ.SERVER.PING.CONNZ"
+	globalAccount          = "$G"
+)
+
+// AuthEvent is a client connecting, disconnecting or failing to
+// authenticate, as reported by a server's system account.
+type AuthEvent struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Server  string    `json:"server"`
+	Account string    `json:"account,omitempty"`
+	User    string    `json:"user,omitempty"`
+	// Name is the connection name the client set, if any.
+	Name   string `json:"name,omitempty"`
+	Host   string `json:"host,omitempty"`
+	CID    uint64 `json:"cid"`
+	Reason string `json:"reason,omitempty"`
+	// Polled is set for global account connections, which the server
+	// does not publish events for; they are found by polling CONNZ.
+	Polled bool `json:"polled,omitempty"`
+}
+
+func (e AuthEvent) String() string {
+	who := e.User
+	if who == "" {
+		who = "?"
+	}
+	if e.Account != "" {
+		who = e.Account + "/" + who
+	}
+	line := fmt.Sprintf("%s %-10s %s from %s", e.Time.Local().Format("15:04:05.000"), strings.ToUpper(e.Kind), who, e.Host)
+	if e.Name != "" {
+		line += fmt.Sprintf(" (%s)", e.Name)
+	}
+	if e.Reason != "" {
+		line += ": " + e.Reason
+	}
+	return line
+}
+
+// AuthEventFilter selects events. Empty fields match everything.
+type AuthEventFilter struct {
+	Kinds    []string
+	Accounts []string
+	Users    []string
+}
+
+// Match reports whether e passes the filter.
+func (f AuthEventFilter) Match(e AuthEvent) bool {
+	in := func(list []string, v string) bool {
+		if len(list) == 0 {
+			return true
+		}
+		for _, item := range list {
+			if item == v {
+				return true
+			}
+		}
+		return false
+	}
+	return in(f.Kinds, e.Kind) && in(f.Accounts, e.Account) && in(f.Users, e.User)
+}
+
+// SystemMonitor streams AuthEvents from a server, connected as a system
+// account user.
+type SystemMonitor struct {
+	nc      *nats.Conn
+	filter  AuthEventFilter
+	handler func(AuthEvent)
+
+	mu   sync.Mutex
+	subs []*nats.Subscription
+	// seen holds the global account connections already reported, by CID
+	// and whether they had closed.
+	seen map[uint64]bool
+	stop chan struct{}
+	done chan struct{}
+}
+
+// WatchSystemEvents subscribes to connect, disconnect and authentication
+// error events on nc, which must belong to the system account, and calls
+// handler for each event that passes filter. Global account connections
+// are polled every poll; zero disables polling.
+func WatchSystemEvents(nc *nats.Conn, filter AuthEventFilter, poll time.Duration, handler func(AuthEvent)) (*SystemMonitor, error) {
+	m := &SystemMonitor{
+		nc:      nc,
+		filter:  filter,
+		handler: handler,
+		seen:    map[uint64]bool{},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	for _, subject := range []string{connectEventSubject, disconnectEventSubject, authErrorEventSubject} {
+		sub, err := nc.Subscribe(subject, m.handleEvent)
+		if err != nil {
+			return nil, m.abort(fmt.Errorf("failed to subscribe to %s: %w", subject, err))
+		}
+		m.subs = append(m.subs, sub)
+	}
+	if err := nc.Flush(); err != nil {
+		return nil, m.abort(fmt.Errorf("failed to subscribe to system events: %w", err))
+	}
+
+	if poll <= 0 {
+		close(m.done)
+		return m, nil
+	}
+	// The first poll reports who is connected now, but not the history of
+	// closed connections.
+	if err := m.pollConnz(true); err != nil {
+		return nil, m.abort(err)
+	}
+	go m.pollLoop(poll)
+	return m, nil
+}
+
+// Stop unsubscribes and stops polling.
+func (m *SystemMonitor) Stop() {
+	for _, sub := range m.subs {
+		sub.Unsubscribe()
+	}
+	select {
+	case <-m.stop:
+	default:
+		close(m.stop)
+	}
+	<-m.done
+}
+
+// abort stops a monitor whose poll loop never started, so nothing else
+// closes done, and returns err.
+func (m *SystemMonitor) abort(err error) error {
+	close(m.done)
+	m.Stop()
+	return err
+}
+
+func (m *SystemMonitor) emit(events ...AuthEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range events {
+		if m.filter.Match(e) {
+			m.handler(e)
+		}
+	}
+}
+
+func (m *SystemMonitor) handleEvent(msg *nats.Msg) {
+	// Connect, disconnect and auth error events share these fields.
+	var ev server.DisconnectEventMsg
+	if err := json.Unmarshal(msg.Data, &ev); err != nil {
+		return
+	}
+	e := AuthEvent{
+		Time:    ev.Time,
+		Server:  ev.Server.Name,
+		Account: ev.Client.Account,
+		User:    ev.Client.User,
+		Name:    ev.Client.Name,
+		Host:    ev.Client.Host,
+		CID:     ev.Client.ID,
+		Reason:  ev.Reason,
+	}
+	switch {
+	case strings.HasSuffix(msg.Subject, ".CLIENT.AUTH.ERR"):
+		e.Kind = EventAuthError
+	case strings.HasSuffix(msg.Subject, ".DISCONNECT"):
+		e.Kind = EventDisconnect
+	default:
+		e.Kind = EventConnect
+	}
+	m.emit(e)
+}
+
+func (m *SystemMonitor) pollLoop(every time.Duration) {
+	defer close(m.done)
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.pollConnz(false)
+		}
+	}
+}
+
+// pollConnz lists global account connections, open and recently closed,
+// and reports the ones not seen before.
+func (m *SystemMonitor) pollConnz(first bool) error {
+	// CONNZ leaves the account of global account connections empty, and
+	// its acc filter cannot select them, so filter here.
+	opts := server.ConnzOptions{State: server.ConnAll, Username: true, Limit: 4096}
+	req, err := json.Marshal(opts)
+	if err != nil {
+		return fmt.Errorf("failed to encode connz request: %w", err)
+	}
+	msg, err := m.nc.Request(connzRequestSubject, req, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to request connz: %w", err)
+	}
+	var resp struct {
+		Server server.ServerInfo `json:"server"`
+		Data   *server.Connz     `json:"data"`
+		Error  *struct {
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(msg.Data, &resp); err != nil {
+		return fmt.Errorf("failed to parse connz response: %w", err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("connz request failed: %s", resp.Error.Description)
+	}
+	if resp.Data == nil {
+		return nil
+	}
+
+	var events []AuthEvent
+	for _, c := range resp.Data.Conns {
+		if c.Account != "" && c.Account != globalAccount {
+			continue
+		}
+		closed := c.Stop != nil
+		// Failed logins are already reported by the auth error event.
+		if closed && c.Reason == server.AuthenticationViolation.String() {
+			continue
+		}
+		if reported, ok := m.seen[c.Cid]; ok && (reported || !closed) {
+			continue
+		}
+		_, wasOpen := m.seen[c.Cid]
+		m.seen[c.Cid] = closed
+		if first && closed {
+			continue
+		}
+
+		e := AuthEvent{
+			Server:  resp.Server.Name,
+			Account: globalAccount,
+			User:    c.AuthorizedUser,
+			Name:    c.Name,
+			Host:    c.IP,
+			CID:     c.Cid,
+			Polled:  true,
+		}
+		if !wasOpen {
+			connect := e
+			connect.Kind = EventConnect
+			connect.Time = c.Start
+			events = append(events, connect)
+		}
+		if closed {
+			disconnect := e
+			disconnect.Kind = EventDisconnect
+			disconnect.Time = *c.Stop
+			disconnect.Reason = c.Reason
+			events = append(events, disconnect)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	m.emit(events...)
+	return nil
+}
@@ -0,0 +1,769 @@
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/conf"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// tenantConfigKeys are the top-level keys LoadAccountsConfig understands.
+var tenantConfigKeys = map[string]bool{
+	"port": true, "max_connections": true, "max_subscriptions": true, "max_payload": true,
+	"accounts": true, "no_auth_user": true, "system_account": true, "mappings": true,
+}
+
+// LoadAccountsConfig reads a multi-account config such as
+// config/accounts.conf into a ServerConfig. It refuses settings
+// ServerConfig can not write back, so regenerating the file never drops
+// them silently; comments are not kept.
+func LoadAccountsConfig(filename string) (*ServerConfig, error) {
+	cfg, err := conf.ParseFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	for key := range cfg {
+		if !tenantConfigKeys[key] {
+			return nil, fmt.Errorf("%s: %q is not supported by the tenant store", filename, key)
+		}
+	}
+
+	c := &ServerConfig{
+		Header: []string{"Multi-tenant accounts, managed with `nats-demo tenant`"},
+		Port:   server.DEFAULT_PORT,
+	}
+	if port, ok := cfg["port"].(int64); ok {
+		c.Port = int(port)
+	}
+	if limits := parseConfigLimits(cfg); limits != (ConfigLimits{}) {
+		c.Limits = &limits
+	}
+	if c.Mappings, err = parseConfigMappings(cfg["mappings"]); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	c.NoAuthUser, _ = cfg["no_auth_user"].(string)
+	c.SystemAccount, _ = cfg["system_account"].(string)
+
+	accounts, ok := cfg["accounts"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s has no accounts block", filename)
+	}
+	for name, v := range accounts {
+		acc, err := parseConfigAccount(name, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: account %s: %w", filename, name, err)
+		}
+		c.Accounts = append(c.Accounts, acc)
+	}
+	sort.Slice(c.Accounts, func(i, j int) bool { return c.Accounts[i].Name < c.Accounts[j].Name })
+	return c, nil
+}
+
+// checkConfigKeys refuses keys of m outside allowed, which are the ones
+// ServerConfig writes back; what names the entry in the error.
+func checkConfigKeys(m map[string]interface{}, what string, allowed ...string) error {
+	for key := range m {
+		if !containsString(allowed, key) {
+			return fmt.Errorf("%s key %q is not supported by the tenant store", what, key)
+		}
+	}
+	return nil
+}
+
+func parseConfigLimits(m map[string]interface{}) ConfigLimits {
+	var l ConfigLimits
+	if v, ok := m["max_connections"].(int64); ok {
+		l.MaxConnections = int(v)
+	}
+	if v, ok := m["max_subscriptions"].(int64); ok {
+		l.MaxSubscriptions = int(v)
+	}
+	if v, ok := m["max_payload"].(int64); ok {
+		l.MaxPayload = int(v)
+	}
+	return l
+}
+
+func parseConfigAccount(name string, v interface{}) (ConfigAccount, error) {
+	acc := ConfigAccount{Name: name}
+	body, _ := v.(map[string]interface{})
+	for key, value := range body {
+		switch key {
+		case "users":
+			for _, u := range configList(value) {
+				user, err := parseConfigUser(u)
+				if err != nil {
+					return acc, err
+				}
+				acc.Users = append(acc.Users, user)
+			}
+		case "limits":
+			m, _ := value.(map[string]interface{})
+			if err := checkConfigKeys(m, "limits", "max_connections", "max_subscriptions", "max_payload"); err != nil {
+				return acc, err
+			}
+			limits := parseConfigLimits(m)
+			acc.Limits = &limits
+		case "exports":
+			for _, e := range configList(value) {
+				export, err := parseConfigExport(e)
+				if err != nil {
+					return acc, err
+				}
+				acc.Exports = append(acc.Exports, export)
+			}
+		case "imports":
+			for _, i := range configList(value) {
+				imp, err := parseConfigImport(i)
+				if err != nil {
+					return acc, err
+				}
+				acc.Imports = append(acc.Imports, imp)
+			}
+		case "mappings":
+			mappings, err := parseConfigMappings(value)
+			if err != nil {
+				return acc, err
+			}
+			acc.Mappings = mappings
+		default:
+			return acc, fmt.Errorf("%q is not supported by the tenant store", key)
+		}
+	}
+	return acc, nil
+}
+
+// parseConfigMappings reads a mappings block: each subject maps to a
+// destination string, one destination map or a list of weighted ones.
+func parseConfigMappings(v interface{}) ([]ConfigMapping, error) {
+	block, _ := v.(map[string]interface{})
+	var mappings []ConfigMapping
+	for subject, value := range block {
+		m := ConfigMapping{Subject: subject}
+		entries := configList(value)
+		switch value := value.(type) {
+		case string:
+			m.Destinations = append(m.Destinations, ConfigMappingDestination{Subject: value})
+		case map[string]interface{}:
+			entries = []interface{}{value}
+		}
+		for _, e := range entries {
+			entry, _ := e.(map[string]interface{})
+			if err := checkConfigKeys(entry, "mapping", "destination", "dest", "weight"); err != nil {
+				return nil, err
+			}
+			d := ConfigMappingDestination{}
+			if d.Subject, _ = entry["destination"].(string); d.Subject == "" {
+				d.Subject, _ = entry["dest"].(string)
+			}
+			switch w := entry["weight"].(type) {
+			case int64:
+				d.Weight = int(w)
+			case string:
+				n, err := strconv.Atoi(strings.TrimSuffix(w, "%"))
+				if err != nil {
+					return nil, fmt.Errorf("mapping of %s: invalid weight %q", subject, w)
+				}
+				d.Weight = n
+			}
+			m.Destinations = append(m.Destinations, d)
+		}
+		if len(m.Destinations) == 0 {
+			return nil, fmt.Errorf("mapping of %s has no destination", subject)
+		}
+		mappings = append(mappings, m)
+	}
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].Subject < mappings[j].Subject })
+	return mappings, nil
+}
+
+func parseConfigUser(v interface{}) (ConfigUser, error) {
+	m, _ := v.(map[string]interface{})
+	var u ConfigUser
+	if err := checkConfigKeys(m, "user", "user", "password", "nkey", "permissions"); err != nil {
+		return u, err
+	}
+	u.User, _ = m["user"].(string)
+	u.Password, _ = m["password"].(string)
+	u.NKey, _ = m["nkey"].(string)
+	if u.User == "" && u.NKey == "" {
+		return u, fmt.Errorf("user entry needs a user or an nkey")
+	}
+	if p, ok := m["permissions"].(map[string]interface{}); ok {
+		if err := checkConfigKeys(p, "permissions", "publish", "subscribe"); err != nil {
+			return u, err
+		}
+		perms := &ConfigPermissions{}
+		var err error
+		if perms.Publish, perms.PublishDeny, err = parsePermissionValue(p["publish"]); err != nil {
+			return u, err
+		}
+		if perms.Subscribe, perms.SubscribeDeny, err = parsePermissionValue(p["subscribe"]); err != nil {
+			return u, err
+		}
+		u.Permissions = perms
+	}
+	return u, nil
+}
+
+// parsePermissionValue reads a subject, a subject list or an allow/deny
+// map.
+func parsePermissionValue(v interface{}) (allow, deny []string, err error) {
+	if m, ok := v.(map[string]interface{}); ok {
+		if err := checkConfigKeys(m, "permission", "allow", "deny"); err != nil {
+			return nil, nil, err
+		}
+		return configSubjects(m["allow"]), configSubjects(m["deny"]), nil
+	}
+	return configSubjects(v), nil, nil
+}
+
+func parseConfigExport(v interface{}) (ConfigExport, error) {
+	m, _ := v.(map[string]interface{})
+	var e ConfigExport
+	if err := checkConfigKeys(m, "export", "stream", "service", "accounts", "latency", "response_type", "response_threshold"); err != nil {
+		return e, err
+	}
+	e.Stream, _ = m["stream"].(string)
+	e.Service, _ = m["service"].(string)
+	e.Accounts = configSubjects(m["accounts"])
+	if lat, ok := m["latency"].(map[string]interface{}); ok {
+		if err := checkConfigKeys(lat, "latency", "subject", "sampling"); err != nil {
+			return e, err
+		}
+		e.Latency = &ConfigLatency{}
+		e.Latency.Results, _ = lat["subject"].(string)
+		switch s := lat["sampling"].(type) {
+		case int64:
+			e.Latency.Sampling = int(s)
+		case string:
+			n, err := strconv.Atoi(strings.TrimSuffix(s, "%"))
+			if err != nil {
+				return e, fmt.Errorf("invalid latency sampling %q", s)
+			}
+			e.Latency.Sampling = n
+		}
+	}
+	if rt, ok := m["response_type"].(string); ok {
+		e.ResponseType = strings.ToLower(rt)
+	}
+	if threshold, ok := m["response_threshold"].(string); ok {
+		d, err := time.ParseDuration(threshold)
+		if err != nil {
+			return e, fmt.Errorf("invalid response threshold %q", threshold)
+		}
+		e.ResponseThreshold = d
+	}
+	if (e.Stream == "") == (e.Service == "") {
+		return e, fmt.Errorf("export needs exactly one of a stream or a service")
+	}
+	return e, nil
+}
+
+func parseConfigImport(v interface{}) (ConfigImport, error) {
+	m, _ := v.(map[string]interface{})
+	var i ConfigImport
+	if err := checkConfigKeys(m, "import", "stream", "service", "prefix", "to"); err != nil {
+		return i, err
+	}
+	src, ok := m["stream"].(map[string]interface{})
+	if ok {
+		i.Stream, _ = src["subject"].(string)
+	} else if src, ok = m["service"].(map[string]interface{}); ok {
+		i.Service, _ = src["subject"].(string)
+	} else {
+		return i, fmt.Errorf("import needs a stream or a service")
+	}
+	if err := checkConfigKeys(src, "import source", "account", "subject"); err != nil {
+		return i, err
+	}
+	i.Account, _ = src["account"].(string)
+	i.Prefix, _ = m["prefix"].(string)
+	i.To, _ = m["to"].(string)
+	return i, nil
+}
+
+// account returns the named account, or nil.
+func (c *ServerConfig) account(name string) *ConfigAccount {
+	for i := range c.Accounts {
+		if c.Accounts[i].Name == name {
+			return &c.Accounts[i]
+		}
+	}
+	return nil
+}
+
+// cloneAccounts copies c deeply enough for tenant changes to edit the
+// copy's accounts, users, exports and imports.
+func (c *ServerConfig) cloneAccounts() *ServerConfig {
+	next := *c
+	next.Accounts = make([]ConfigAccount, len(c.Accounts))
+	for i, acc := range c.Accounts {
+		acc.Users = append([]ConfigUser(nil), acc.Users...)
+		acc.Imports = append([]ConfigImport(nil), acc.Imports...)
+		acc.Exports = append([]ConfigExport(nil), acc.Exports...)
+		for j := range acc.Exports {
+			acc.Exports[j].Accounts = append([]string(nil), acc.Exports[j].Accounts...)
+		}
+		next.Accounts[i] = acc
+	}
+	return &next
+}
+
+// TenantChangeError is returned for a change that would leave the config
+// invalid. Neither the file nor the server is touched.
+type TenantChangeError struct {
+	Issues []AccountIssue
+}
+
+func (e *TenantChangeError) Error() string {
+	lines := []string{fmt.Sprintf("change rejected with %d issue(s):", len(e.Issues))}
+	for _, issue := range e.Issues {
+		lines = append(lines, "  "+issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkTenants validates the accounts of c: the import/export checks of
+// ValidateAccounts, plus users defined twice and a no_auth_user or system
+// account that does not exist.
+func checkTenants(c *ServerConfig) ([]AccountIssue, error) {
+	data, err := c.Render()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := conf.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse generated config: %w", err)
+	}
+	g, err := accountGraph(cfg, "generated config")
+	if err != nil {
+		return nil, err
+	}
+	issues := ValidateAccounts(g)
+
+	owner := map[string]string{}
+	names := map[string]bool{}
+	for _, acc := range c.Accounts {
+		if names[acc.Name] {
+			issues = append(issues, AccountIssue{acc.Name, "duplicate-account", "account is defined twice"})
+		}
+		names[acc.Name] = true
+		for _, u := range acc.Users {
+			name := u.User
+			if name == "" {
+				name = u.NKey
+			}
+			if other, ok := owner[name]; ok {
+				issues = append(issues, AccountIssue{acc.Name, "duplicate-user",
+					fmt.Sprintf("user %s is already a user of account %s", name, other)})
+				continue
+			}
+			owner[name] = acc.Name
+		}
+	}
+	if c.NoAuthUser != "" && owner[c.NoAuthUser] == "" {
+		issues = append(issues, AccountIssue{"no_auth_user", "missing-user",
+			fmt.Sprintf("no_auth_user %s is not a user of any account", c.NoAuthUser)})
+	}
+	if c.SystemAccount != "" && !names[c.SystemAccount] {
+		issues = append(issues, AccountIssue{c.SystemAccount, "missing-account", "system_account does not exist"})
+	}
+	return issues, nil
+}
+
+// TenantStore keeps the accounts of a config file. Each change is
+// validated, written to the file and applied to the running server with
+// Reload; a change that fails any step leaves both as they were.
+type TenantStore struct {
+	File   string
+	Config *ServerConfig
+	// Reload applies the rewritten file to a running server. Nil only
+	// writes the file.
+	Reload func(file string) error
+}
+
+// OpenTenantStore loads the accounts of file.
+func OpenTenantStore(file string) (*TenantStore, error) {
+	c, err := LoadAccountsConfig(file)
+	if err != nil {
+		return nil, err
+	}
+	return &TenantStore{File: file, Config: c}, nil
+}
+
+// Apply runs change on a copy of the config, then validates, writes and
+// reloads it.
+func (t *TenantStore) Apply(change func(c *ServerConfig) error) error {
+	next := t.Config.cloneAccounts()
+	if err := change(next); err != nil {
+		return err
+	}
+	issues, err := checkTenants(next)
+	if err != nil {
+		return err
+	}
+	if len(issues) > 0 {
+		return &TenantChangeError{Issues: issues}
+	}
+
+	// The server must accept the new file before it replaces the old one.
+	tmp, err := os.CreateTemp(filepath.Dir(t.File), ".tenants-*.conf")
+	if err != nil {
+		return fmt.Errorf("failed to create config: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	if err := next.WriteFile(tmp.Name()); err != nil {
+		return err
+	}
+	// CreateTemp makes the file owner-only; keep the mode of the file it
+	// replaces so a server running as another user can still read it.
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(t.File); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to set config permissions: %w", err)
+	}
+	if _, err := server.ProcessConfigFile(tmp.Name()); err != nil {
+		return fmt.Errorf("server rejects the new config: %w", err)
+	}
+
+	previous, err := os.ReadFile(t.File)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), t.File); err != nil {
+		return fmt.Errorf("failed to replace config: %w", err)
+	}
+	if t.Reload != nil {
+		if err := t.Reload(t.File); err != nil {
+			if werr := os.WriteFile(t.File, previous, 0644); werr != nil {
+				return fmt.Errorf("failed to reload server (%v) and to restore the previous config: %w", err, werr)
+			}
+			return fmt.Errorf("failed to reload server, previous config restored: %w", err)
+		}
+	}
+	t.Config = next
+	return nil
+}
+
+// AddTenant adds an account with its users, exports and imports.
+func (t *TenantStore) AddTenant(acc ConfigAccount) error {
+	return t.Apply(func(c *ServerConfig) error {
+		if c.account(acc.Name) != nil {
+			return fmt.Errorf("account %s already exists", acc.Name)
+		}
+		c.Accounts = append(c.Accounts, acc)
+		sort.Slice(c.Accounts, func(i, j int) bool { return c.Accounts[i].Name < c.Accounts[j].Name })
+		return nil
+	})
+}
+
+// RemoveTenant removes an account. Private exports of other accounts stop
+// listing it, and one that listed only it is removed rather than becoming
+// public. Removing an account others import from is rejected.
+func (t *TenantStore) RemoveTenant(name string) error {
+	return t.Apply(func(c *ServerConfig) error {
+		if c.account(name) == nil {
+			return fmt.Errorf("account %s does not exist", name)
+		}
+		if name == c.SystemAccount {
+			return fmt.Errorf("account %s is the system account", name)
+		}
+
+		var accounts []ConfigAccount
+		for _, acc := range c.Accounts {
+			if acc.Name == name {
+				continue
+			}
+			var exports []ConfigExport
+			for _, e := range acc.Exports {
+				if len(e.Accounts) > 0 {
+					var keep []string
+					for _, a := range e.Accounts {
+						if a != name {
+							keep = append(keep, a)
+						}
+					}
+					if len(keep) == 0 {
+						continue
+					}
+					e.Accounts = keep
+				}
+				exports = append(exports, e)
+			}
+			acc.Exports = exports
+			accounts = append(accounts, acc)
+		}
+		c.Accounts = accounts
+		return nil
+	})
+}
+
+// GrantImport adds imp to the importer, replacing an import of the same
+// subject from the same account. If the matching export is private, the
+// importer is added to the accounts it lists.
+func (t *TenantStore) GrantImport(importer string, imp ConfigImport) error {
+	return t.Apply(func(c *ServerConfig) error {
+		acc := c.account(importer)
+		if acc == nil {
+			return fmt.Errorf("account %s does not exist", importer)
+		}
+
+		if exporter := c.account(imp.Account); exporter != nil {
+			for i, e := range exporter.Exports {
+				covers := func(pattern, subject string) bool {
+					return pattern != "" && subject != "" && (pattern == subject || subjectCovers(pattern, subject))
+				}
+				if !covers(e.Stream, imp.Stream) && !covers(e.Service, imp.Service) {
+					continue
+				}
+				if len(e.Accounts) > 0 && !containsString(e.Accounts, importer) {
+					exporter.Exports[i].Accounts = append(e.Accounts, importer)
+				}
+				break
+			}
+		}
+
+		var imports []ConfigImport
+		for _, i := range acc.Imports {
+			if i.Account != imp.Account || i.Stream != imp.Stream || i.Service != imp.Service {
+				imports = append(imports, i)
+			}
+		}
+		acc.Imports = append(imports, imp)
+		return nil
+	})
+}
+
+// EmbeddedReloader reloads an embedded server from the config file,
+// keeping the address it listens on, which can not change on reload.
+func EmbeddedReloader(s *server.Server) func(file string) error {
+	return func(file string) error {
+		opts, err := server.ProcessConfigFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", file, err)
+		}
+		return reloadEmbeddedServer(s, opts)
+	}
+}
+
+// reloadEmbeddedServer applies opts to a server started with
+// startEmbeddedServer, keeping its listen address and the settings
+// startEmbeddedServer forces.
+func reloadEmbeddedServer(s *server.Server, opts *server.Options) error {
+	if addr, ok := s.Addr().(*net.TCPAddr); ok {
+		opts.Host = addr.IP.String()
+		opts.Port = addr.Port
+	}
+	opts.NoSigs = true
+	opts.NoLog = true
+	if err := s.ReloadOptions(opts); err != nil {
+		return fmt.Errorf("failed to reload server: %w", err)
+	}
+	return nil
+}
+
+// SignalReloader signals the nats-server process pid to reload its config
+// file, like `nats-server --signal reload=PID`. An empty pid signals the
+// only nats-server running. The server must have been started with the
+// file the store writes.
+//
+// It returns once the signal is sent: a reload the server then rejects
+// is only logged by the server, and Apply keeps the new file. Use
+// SystemReloader to have failures restore the previous config.
+func SignalReloader(pid string) func(file string) error {
+	return func(string) error {
+		if err := server.ProcessSignal(server.CommandReload, pid); err != nil {
+			return fmt.Errorf("failed to signal nats-server: %w", err)
+		}
+		return nil
+	}
+}
+
+// SystemReloader asks the server nc is connected to, as a system account
+// user, to reload its config file and waits for the answer, so a reload
+// the server rejects makes Apply restore the previous config.
+func SystemReloader(nc *nats.Conn) func(file string) error {
+	return func(string) error {
+		subject := fmt.Sprintf("$SYS.REQ.SERVER.%s.RELOAD", nc.ConnectedServerId())
+		msg, err := nc.Request(subject, nil, 5*time.Second)
+		if err != nil {
+			return fmt.Errorf("failed to request reload: %w", err)
+		}
+		var resp server.ServerAPIResponse
+		if err := json.Unmarshal(msg.Data, &resp); err != nil {
+			return fmt.Errorf("failed to parse reload response: %w", err)
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("server refused to reload: %s", resp.Error.Description)
+		}
+		return nil
+	}
+}
+
+// ReadPIDFile returns the pid nats-server wrote to its pid_file.
+func ReadPIDFile(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pid file: %w", err)
+	}
+	pid := strings.TrimSpace(string(data))
+	if _, err := strconv.Atoi(pid); err != nil {
+		return "", fmt.Errorf("invalid pid in %s: %q", filename, pid)
+	}
+	return pid, nil
+}
+
+// DemoTenantProvisioning demonstrates adding, granting and removing a
+// tenant while clients of the other accounts stay connected
+func DemoTenantProvisioning() {
+	fmt.Println("\n=== Tenant Provisioning with Live Reload Demo ===")
+
+	dir, err := os.MkdirTemp("", "nats-tenants-")
+	if err != nil {
+		log.Printf("Failed to create config dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cfg, err := LoadAccountsConfig("config/accounts.conf")
+	if err != nil {
+		log.Printf("Failed to load config/accounts.conf: %v", err)
+		return
+	}
+	cfg.Port = server.RANDOM_PORT
+	configFile := filepath.Join(dir, "accounts.conf")
+	if err := cfg.WriteFile(configFile); err != nil {
+		log.Printf("Failed to write config: %v", err)
+		return
+	}
+	store, err := OpenTenantStore(configFile)
+	if err != nil {
+		log.Printf("Tenant store failed: %v", err)
+		return
+	}
+
+	opts, err := server.ProcessConfigFile(configFile)
+	if err != nil {
+		log.Printf("Failed to load config: %v", err)
+		return
+	}
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	store.Reload = EmbeddedReloader(s)
+	url := s.ClientURL()
+
+	fmt.Println("\n1. Server running config/accounts.conf (accounts A, B, C, SYS)")
+	connA, err := nats.Connect(url, userPassword("user_a", "pass_a"))
+	if err != nil {
+		log.Printf("Account A connection failed: %v", err)
+		return
+	}
+	defer connA.Close()
+	connB, err := nats.Connect(url, userPassword("user_b", "pass_b"))
+	if err != nil {
+		log.Printf("Account B connection failed: %v", err)
+		return
+	}
+	defer connB.Close()
+	subB, _ := connB.SubscribeSync("b.data")
+	connB.Flush()
+	if nc, err := nats.Connect(url, userPassword("user_d", "pass_d")); err != nil {
+		fmt.Printf("✗ user_d rejected: %v\n", err)
+	} else {
+		nc.Close()
+		fmt.Println("❌ user_d connected before account D exists")
+	}
+
+	fmt.Println("\n2. Adding account D with user_d and a public service export d.status:")
+	err = store.AddTenant(ConfigAccount{
+		Name:    "D",
+		Users:   []ConfigUser{{User: "user_d", Password: "pass_d"}},
+		Exports: []ConfigExport{{Service: "d.status"}},
+	})
+	if err != nil {
+		log.Printf("Add tenant failed: %v", err)
+		return
+	}
+	fmt.Println("✓ Config validated, rewritten and reloaded")
+	connD, err := nats.Connect(url, userPassword("user_d", "pass_d"))
+	if err != nil {
+		fmt.Printf("❌ user_d connection failed: %v\n", err)
+		return
+	}
+	defer connD.Close()
+	fmt.Println("✓ user_d connected to account D")
+	if connA.IsConnected() && connB.IsConnected() {
+		fmt.Println("✓ A and B kept their connections through the reload")
+	}
+
+	fmt.Println("\n3. Granting D an import of A's private stream b.>:")
+	if err := store.GrantImport("D", ConfigImport{Account: "A", Stream: "b.>"}); err != nil {
+		log.Printf("Grant import failed: %v", err)
+		return
+	}
+	fmt.Println("✓ A's b.> export now lists B and D; D imports it")
+	subD, _ := connD.SubscribeSync("b.data")
+	connD.Flush()
+	expectStream(connA, subD, "b.data", "Account D", true)
+	expectStream(connA, subB, "b.data", "Account B", true)
+
+	fmt.Println("\n4. Changes that fail validation are refused:")
+	before, _ := os.ReadFile(configFile)
+	if err := store.GrantImport("D", ConfigImport{Account: "A", Service: "q.c"}); err != nil {
+		fmt.Printf("✗ D importing service q.c: %v\n", err)
+	}
+	if err := store.RemoveTenant("A"); err != nil {
+		fmt.Printf("✗ Removing A: %v\n", err)
+	}
+	if err := store.AddTenant(ConfigAccount{Name: "E", Users: []ConfigUser{{User: "user_a", Password: "x"}}}); err != nil {
+		fmt.Printf("✗ Adding E with user_a: %v\n", err)
+	}
+	if after, _ := os.ReadFile(configFile); string(after) == string(before) {
+		fmt.Println("✓ Config file unchanged")
+	}
+
+	fmt.Println("\n5. Removing account D:")
+	dropped := make(chan error, 1)
+	connD.SetDisconnectErrHandler(func(_ *nats.Conn, err error) {
+		select {
+		case dropped <- err:
+		default:
+		}
+	})
+	if err := store.RemoveTenant("D"); err != nil {
+		log.Printf("Remove tenant failed: %v", err)
+		return
+	}
+	fmt.Println("✓ Config validated, rewritten and reloaded")
+	select {
+	case err := <-dropped:
+		fmt.Printf("✓ Server dropped user_d's connection: %v\n", err)
+	case <-time.After(2 * time.Second):
+		fmt.Println("❌ user_d is still connected")
+	}
+	for _, e := range store.Config.account("A").Exports {
+		if e.Stream == "b.>" {
+			fmt.Printf("✓ A's b.> export is private to %s again\n", strings.Join(e.Accounts, ", "))
+		}
+	}
+
+	fmt.Println("\n=== Tenant Provisioning with Live Reload Demo Complete ===")
+}
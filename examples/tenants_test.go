@@ -0,0 +1,76 @@
+package examples
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+func TestAccountsConfigRoundTrip(t *testing.T) {
+	c, err := LoadAccountsConfig("../config/accounts.conf")
+	if err != nil {
+		t.Fatalf("LoadAccountsConfig failed: %v", err)
+	}
+	first, err := c.Render()
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "tenants.conf")
+	if err := c.WriteFile(file); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := server.ProcessConfigFile(file); err != nil {
+		t.Fatalf("server rejects the rendered config: %v", err)
+	}
+
+	again, err := LoadAccountsConfig(file)
+	if err != nil {
+		t.Fatalf("LoadAccountsConfig of the rendered config failed: %v", err)
+	}
+	second, err := again.Render()
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatalf("render changed after a round trip:\n%s\n---\n%s", first, second)
+	}
+
+	g, err := LoadAccountGraph(file)
+	if err != nil {
+		t.Fatalf("LoadAccountGraph failed: %v", err)
+	}
+	if issues := ValidateAccounts(g); len(issues) != 0 {
+		t.Fatalf("rendered config has issues: %v", issues)
+	}
+	if len(g.Accounts) != 4 || len(g.Edges) != 4 {
+		t.Fatalf("rendered config has %d accounts and %d imports, want 4 and 4", len(g.Accounts), len(g.Edges))
+	}
+}
+
+func TestLoadAccountsConfigRejectsUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name, config, want string
+	}{
+		{"authorization block", "authorization { users = [{user: a, password: b}] }", `"authorization"`},
+		{"user setting", "accounts { A { users = [{user: a, password: b, allowed_connection_types: [STANDARD]}] } }", "allowed_connection_types"},
+		{"permission setting", "accounts { A { users = [{user: a, password: b, permissions: {allow_responses: true}}] } }", "allow_responses"},
+		{"export setting", "accounts { A { exports = [{service: q, account_token_position: 2}] } }", "account_token_position"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "accounts.conf")
+			if err := os.WriteFile(file, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadAccountsConfig(file)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("LoadAccountsConfig error = %v, want one naming %s", err, tt.want)
+			}
+		})
+	}
+}
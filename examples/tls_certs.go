@@ -0,0 +1,236 @@
+package examples
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTLSDir is where the cert tool and the TLS demo keep their files.
+const DefaultTLSDir = "generated/tls"
+
+// DefaultOCSPURL is the OCSP responder address baked into demo certificates.
+const DefaultOCSPURL = "http://127.0.0.1:8889"
+
+// Certificate is a certificate and its private key.
+type Certificate struct {
+	Cert *x509.Certificate
+	Key  *ecdsa.PrivateKey
+}
+
+// CertRequest describes a certificate to issue. A client certificate's
+// identity is its first email SAN, or its first DNS SAN when it has no email.
+// OCSPServer lists the responders servers should ask about revocation.
+type CertRequest struct {
+	CommonName     string
+	DNSNames       []string
+	IPAddresses    []net.IP
+	EmailAddresses []string
+	OCSPServer     []string
+	Client         bool
+	NotBefore      time.Time
+	NotAfter       time.Time
+}
+
+// NewCertAuthority creates a self-signed CA valid for validFor.
+func NewCertAuthority(commonName string, validFor time.Duration) (*Certificate, error) {
+	now := time.Now()
+	return createCertificate(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"NATS Auth Demo"}},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil)
+}
+
+// Issue signs a server or client certificate with the CA. A zero NotBefore
+// means now; a zero NotAfter means one year after NotBefore.
+func (ca *Certificate) Issue(req CertRequest) (*Certificate, error) {
+	notBefore := req.NotBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now().Add(-time.Minute)
+	}
+	notAfter := req.NotAfter
+	if notAfter.IsZero() {
+		notAfter = notBefore.Add(365 * 24 * time.Hour)
+	}
+
+	usage := x509.ExtKeyUsageServerAuth
+	if req.Client {
+		usage = x509.ExtKeyUsageClientAuth
+	}
+
+	return createCertificate(&x509.Certificate{
+		Subject:        pkix.Name{CommonName: req.CommonName, Organization: []string{"NATS Auth Demo"}},
+		DNSNames:       req.DNSNames,
+		IPAddresses:    req.IPAddresses,
+		EmailAddresses: req.EmailAddresses,
+		OCSPServer:     req.OCSPServer,
+		NotBefore:      notBefore,
+		NotAfter:       notAfter,
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{usage},
+	}, ca)
+}
+
+// createCertificate signs template with parent, or self-signs it when
+// parent is nil.
+func createCertificate(template *x509.Certificate, parent *Certificate) (*Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	template.SerialNumber = serial
+
+	signerCert, signerKey := template, key
+	if parent != nil {
+		signerCert, signerKey = parent.Cert, parent.Key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return &Certificate{Cert: cert, Key: key}, nil
+}
+
+// CertFiles returns the certificate and key file names for name in dir.
+func CertFiles(dir, name string) (certFile, keyFile string) {
+	return filepath.Join(dir, name+"-cert.pem"), filepath.Join(dir, name+"-key.pem")
+}
+
+// WriteFiles writes the certificate and key as PEM to dir. The key file is
+// only readable by the owner.
+func (c *Certificate) WriteFiles(dir, name string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(c.Key)
+	if err != nil {
+		return fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	certFile, keyFile := CertFiles(dir, name)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Cert.Raw})
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return fmt.Errorf("failed to write certificate: %w", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return fmt.Errorf("failed to write key: %w", err)
+	}
+	return nil
+}
+
+// LoadCertificate reads a certificate and key written by WriteFiles.
+func LoadCertificate(dir, name string) (*Certificate, error) {
+	certFile, keyFile := CertFiles(dir, name)
+
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s does not contain a PEM certificate", certFile)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ = pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM key", keyFile)
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key: %w", err)
+	}
+	return &Certificate{Cert: cert, Key: key}, nil
+}
+
+// CertIdentity returns the name verify_and_map matches against the users
+// list: the first email SAN, then the first DNS SAN, then the subject DN.
+func CertIdentity(cert *x509.Certificate) string {
+	switch {
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	}
+	return cert.Subject.String()
+}
+
+// demoClientCerts are the client identities mapped in config/tls-mapping.conf.
+var demoClientCerts = []struct {
+	name string
+	req  CertRequest
+}{
+	{"admin", CertRequest{CommonName: "Admin", EmailAddresses: []string{"admin@demo.local"}, Client: true}},
+	{"client", CertRequest{CommonName: "Client", EmailAddresses: []string{"client@demo.local"}, Client: true}},
+	{"service", CertRequest{CommonName: "Service", DNSNames: []string{"service.demo.local"}, Client: true}},
+}
+
+// GenerateDemoCerts creates the CA, server and client certificates
+// config/tls-mapping.conf expects in dir. Client certificates point at the
+// OCSP responder on DefaultOCSPURL.
+func GenerateDemoCerts(dir string) error {
+	ca, err := NewCertAuthority("NATS Demo CA", 5*365*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	if err := ca.WriteFiles(dir, "ca"); err != nil {
+		return err
+	}
+
+	srv, err := ca.Issue(CertRequest{
+		CommonName:  "localhost",
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+	})
+	if err != nil {
+		return err
+	}
+	if err := srv.WriteFiles(dir, "server"); err != nil {
+		return err
+	}
+
+	for _, c := range demoClientCerts {
+		req := c.req
+		req.OCSPServer = []string{DefaultOCSPURL}
+		cert, err := ca.Issue(req)
+		if err != nil {
+			return err
+		}
+		if err := cert.WriteFiles(dir, c.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package examples
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// certOptions returns the options to connect with the client certificate
+// name from dir, trusting the CA in caDir.
+func certOptions(caDir, dir, name string) []nats.Option {
+	caFile, _ := CertFiles(caDir, "ca")
+	certFile, keyFile := CertFiles(dir, name)
+	return []nats.Option{nats.RootCAs(caFile), nats.ClientCert(certFile, keyFile)}
+}
+
+// forcedCertOptions is like certOptions, but presents the certificate even
+// when the server asks for one from a CA that did not issue it. Go's TLS
+// client otherwise sends no certificate at all, and the server would
+// reject the connection for lacking one rather than for its chain.
+func forcedCertOptions(caDir, dir, name string) ([]nats.Option, error) {
+	caFile, _ := CertFiles(caDir, "ca")
+	certFile, keyFile := CertFiles(dir, name)
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s certificate: %w", name, err)
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in %s", caFile)
+	}
+	return []nats.Option{nats.Secure(&tls.Config{
+		RootCAs:    roots,
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &pair, nil
+		},
+	})}, nil
+}
+
+// ensureDemoCerts generates the demo certificates unless they already
+// exist, and returns the CA.
+func ensureDemoCerts() (*Certificate, error) {
+	caFile, _ := CertFiles(DefaultTLSDir, "ca")
+	if _, err := os.Stat(caFile); err != nil {
+		if err := GenerateDemoCerts(DefaultTLSDir); err != nil {
+			return nil, err
+		}
+		fmt.Printf("✓ Generated CA, server and client certificates in %s\n", DefaultTLSDir)
+	} else {
+		fmt.Printf("✓ Using existing certificates in %s\n", DefaultTLSDir)
+	}
+	return LoadCertificate(DefaultTLSDir, "ca")
+}
+
+// DemoTLSMapping demonstrates mutual TLS with verify_and_map, where the
+// client certificate alone decides which user, and so which permissions,
+// a connection gets
+func DemoTLSMapping() {
+	fmt.Println("\n=== TLS Certificate Mapping Demo ===")
+
+	fmt.Println("\n1. Preparing certificates:")
+	ca, err := ensureDemoCerts()
+	if err != nil {
+		log.Printf("Failed to prepare certificates: %v", err)
+		return
+	}
+	caFile, _ := CertFiles(DefaultTLSDir, "ca")
+
+	// Certificates the server should refuse live in a temp dir.
+	dir, err := os.MkdirTemp("", "nats-tls-")
+	if err != nil {
+		log.Printf("Failed to create temp dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	rogueCA, err := NewCertAuthority("Rogue CA", time.Hour)
+	if err != nil {
+		log.Printf("Failed to create rogue CA: %v", err)
+		return
+	}
+	bad := []struct {
+		name   string
+		issuer *Certificate
+		req    CertRequest
+	}{
+		{"rogue", rogueCA, CertRequest{CommonName: "Admin", EmailAddresses: []string{"admin@demo.local"}, Client: true}},
+		{"expired", ca, CertRequest{
+			CommonName: "Admin", EmailAddresses: []string{"admin@demo.local"}, Client: true,
+			NotBefore: time.Now().Add(-48 * time.Hour), NotAfter: time.Now().Add(-24 * time.Hour),
+		}},
+		{"unmapped", ca, CertRequest{CommonName: "Guest", EmailAddresses: []string{"guest@demo.local"}, Client: true}},
+	}
+	for _, b := range bad {
+		cert, err := b.issuer.Issue(b.req)
+		if err != nil {
+			log.Printf("Failed to issue %s certificate: %v", b.name, err)
+			return
+		}
+		if err := cert.WriteFiles(dir, b.name); err != nil {
+			log.Printf("Failed to write %s certificate: %v", b.name, err)
+			return
+		}
+	}
+	fmt.Println("✓ Issued rogue-CA, expired and unmapped client certificates")
+
+	opts, err := server.ProcessConfigFile("config/tls-mapping.conf")
+	if err != nil {
+		log.Printf("Failed to load config/tls-mapping.conf: %v", err)
+		return
+	}
+	opts.Port = server.RANDOM_PORT
+
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	url := s.ClientURL()
+
+	fmt.Println("\n2. Certificates choose the permissions:")
+	for _, c := range demoClientCerts {
+		cert, err := LoadCertificate(DefaultTLSDir, c.name)
+		if err != nil {
+			log.Printf("Failed to load %s certificate: %v", c.name, err)
+			return
+		}
+		fmt.Printf("  %s certificate, identity %s\n", c.name, CertIdentity(cert.Cert))
+	}
+
+	admin, err := nats.Connect(url, certOptions(DefaultTLSDir, DefaultTLSDir, "admin")...)
+	if err != nil {
+		log.Printf("Admin connection failed: %v", err)
+		return
+	}
+	defer admin.Close()
+	fmt.Println("✓ Admin connected with its certificate (ADMIN role)")
+
+	service, err := nats.Connect(url, certOptions(DefaultTLSDir, DefaultTLSDir, "service")...)
+	if err != nil {
+		log.Printf("Service connection failed: %v", err)
+		return
+	}
+	defer service.Close()
+	fmt.Println("✓ Service connected with its certificate (RESPONDER role)")
+
+	service.Subscribe("req.a", func(m *nats.Msg) {
+		m.Respond([]byte("response from service"))
+	})
+	service.Flush()
+
+	client, err := nats.Connect(url, certOptions(DefaultTLSDir, DefaultTLSDir, "client")...)
+	if err != nil {
+		log.Printf("Client connection failed: %v", err)
+		return
+	}
+	defer client.Close()
+	fmt.Println("✓ Client connected with its certificate (REQUESTOR role)")
+
+	if reply, err := client.Request("req.a", []byte("hello"), 2*time.Second); err != nil {
+		log.Printf("Client request failed: %v", err)
+	} else {
+		fmt.Printf("✓ Client request to req.a answered: %s\n", reply.Data)
+	}
+
+	clientErrs := asyncErrors(client)
+	client.Publish("admin.commands", []byte("shutdown"))
+	client.Flush()
+	if err := waitError(clientErrs, time.Second); err != nil {
+		fmt.Printf("✗ Client cannot publish to admin.commands: %v\n", err)
+	}
+
+	serviceErrs := asyncErrors(service)
+	service.Subscribe("admin.commands", func(*nats.Msg) {})
+	service.Flush()
+	if err := waitError(serviceErrs, time.Second); err != nil {
+		fmt.Printf("✗ Service cannot subscribe to admin.commands: %v\n", err)
+	}
+
+	sub, err := admin.SubscribeSync("admin.commands")
+	if err == nil {
+		admin.Publish("admin.commands", []byte("status"))
+		if _, err := sub.NextMsg(time.Second); err == nil {
+			fmt.Println("✓ Admin can publish and subscribe to admin.commands")
+		}
+	}
+
+	fmt.Println("\n3. Certificates the server rejects:")
+	rogue, err := forcedCertOptions(DefaultTLSDir, dir, "rogue")
+	if err != nil {
+		log.Printf("Failed to prepare rogue certificate: %v", err)
+		return
+	}
+	rejections := []struct {
+		label string
+		opts  []nats.Option
+	}{
+		{"Certificate from another CA", rogue},
+		{"Expired certificate", certOptions(DefaultTLSDir, dir, "expired")},
+		{"Valid certificate with no mapped user", certOptions(DefaultTLSDir, dir, "unmapped")},
+		{"No client certificate", []nats.Option{nats.RootCAs(caFile)}},
+	}
+	for _, r := range rejections {
+		if nc, err := nats.Connect(url, r.opts...); err != nil {
+			fmt.Printf("✗ %s rejected: %v\n", r.label, err)
+		} else {
+			fmt.Printf("❌ %s was accepted\n", r.label)
+			nc.Close()
+		}
+	}
+
+	fmt.Println("\n=== TLS Certificate Mapping Demo Complete ===")
+}
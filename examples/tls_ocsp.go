@@ -0,0 +1,305 @@
+package examples
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"golang.org/x/crypto/ocsp"
+)
+
+// DefaultRevocationFile lists the client certificates the demo CA revoked.
+const DefaultRevocationFile = "generated/tls/revoked.json"
+
+// RevokedCert is one entry of a revocation list.
+type RevokedCert struct {
+	Serial    string    `json:"serial"`
+	Subject   string    `json:"subject"`
+	RevokedAt time.Time `json:"revoked_at"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// RevocationList is the CA's record of revoked certificates, kept as JSON.
+type RevocationList struct {
+	path    string
+	Revoked []RevokedCert `json:"revoked"`
+}
+
+// LoadRevocationList reads the list at path. A missing file is an empty
+// list.
+func LoadRevocationList(path string) (*RevocationList, error) {
+	list := &RevocationList{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revocation list: %w", err)
+	}
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("failed to parse revocation list: %w", err)
+	}
+	return list, nil
+}
+
+// Save writes the list back to the file it was loaded from.
+func (l *RevocationList) Save() error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode revocation list: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(l.path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write revocation list: %w", err)
+	}
+	return nil
+}
+
+// Revoke adds cert to the list. Revoking a certificate twice keeps the
+// first entry.
+func (l *RevocationList) Revoke(cert *x509.Certificate, reason string) *RevokedCert {
+	if r := l.Find(cert.SerialNumber); r != nil {
+		return r
+	}
+	l.Revoked = append(l.Revoked, RevokedCert{
+		Serial:    cert.SerialNumber.Text(16),
+		Subject:   CertIdentity(cert),
+		RevokedAt: time.Now().UTC().Truncate(time.Second),
+		Reason:    reason,
+	})
+	return &l.Revoked[len(l.Revoked)-1]
+}
+
+// Find returns the entry for serial, or nil if it is not revoked.
+func (l *RevocationList) Find(serial *big.Int) *RevokedCert {
+	hex := serial.Text(16)
+	for i := range l.Revoked {
+		if l.Revoked[i].Serial == hex {
+			return &l.Revoked[i]
+		}
+	}
+	return nil
+}
+
+// OCSPResponder is a minimal stand-in for a CA's OCSP responder. It answers
+// for certificates issued by CA, signing with the CA key, and re-reads the
+// revocation list on every request so revocations apply immediately.
+type OCSPResponder struct {
+	CA             *Certificate
+	RevocationFile string
+	// Validity is how long a response may be cached; it sets NextUpdate.
+	Validity time.Duration
+
+	// Decisions, if set, is told the serial and status of every answer.
+	Decisions func(serial, status string)
+}
+
+// ServeHTTP answers OCSP requests sent by GET (base64 in the path) or POST.
+func (o *OCSPResponder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var der []byte
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		var path string
+		path, err = url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/"))
+		if err == nil {
+			der, err = base64.StdEncoding.DecodeString(path)
+		}
+	case http.MethodPost:
+		der, err = io.ReadAll(io.LimitReader(r.Body, 64*1024))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "malformed request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := o.respond(der)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/ocsp-response")
+	w.Write(resp)
+}
+
+func (o *OCSPResponder) respond(der []byte) ([]byte, error) {
+	req, err := ocsp.ParseRequest(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OCSP request: %w", err)
+	}
+
+	list, err := LoadRevocationList(o.RevocationFile)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	template := ocsp.Response{
+		Status:       ocsp.Good,
+		SerialNumber: req.SerialNumber,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(o.Validity),
+	}
+	if o.Validity <= 0 {
+		template.NextUpdate = now.Add(time.Hour)
+	}
+	if revoked := list.Find(req.SerialNumber); revoked != nil {
+		template.Status = ocsp.Revoked
+		template.RevokedAt = revoked.RevokedAt
+		template.RevocationReason = ocsp.Unspecified
+	}
+
+	if o.Decisions != nil {
+		status := "good"
+		if template.Status == ocsp.Revoked {
+			status = "revoked"
+		}
+		o.Decisions(req.SerialNumber.Text(16), status)
+	}
+
+	resp, err := ocsp.CreateResponse(o.CA.Cert, o.CA.Cert, template, o.CA.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign OCSP response: %w", err)
+	}
+	return resp, nil
+}
+
+// DemoTLSRevocation demonstrates revoking a client certificate: the server
+// asks the OCSP responder about every client certificate, so a revoked one
+// can no longer connect while a freshly issued one can
+func DemoTLSRevocation() {
+	fmt.Println("\n=== TLS Certificate Revocation Demo ===")
+
+	fmt.Println("\n1. Preparing certificates and the OCSP responder:")
+	ca, err := ensureDemoCerts()
+	if err != nil {
+		log.Printf("Failed to prepare certificates: %v", err)
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "nats-ocsp-")
+	if err != nil {
+		log.Printf("Failed to create temp dir: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Printf("Failed to listen for the OCSP responder: %v", err)
+		return
+	}
+	responder := &OCSPResponder{
+		CA:             ca,
+		RevocationFile: filepath.Join(dir, "revoked.json"),
+		Validity:       time.Minute,
+		Decisions: func(serial, status string) {
+			fmt.Printf("  [ocsp] %s...: %s\n", serial[:12], status)
+		},
+	}
+	httpServer := &http.Server{Handler: responder}
+	go httpServer.Serve(listener)
+	defer httpServer.Close()
+	responderURL := "http://" + listener.Addr().String()
+	fmt.Printf("✓ OCSP responder listening on %s\n", responderURL)
+
+	// Both certificates carry the same identity; only the serial differs.
+	certs := make(map[string]*Certificate)
+	for _, name := range []string{"old", "fresh"} {
+		cert, err := ca.Issue(CertRequest{
+			CommonName:     "Client",
+			EmailAddresses: []string{"client@demo.local"},
+			OCSPServer:     []string{responderURL},
+			Client:         true,
+		})
+		if err != nil {
+			log.Printf("Failed to issue %s certificate: %v", name, err)
+			return
+		}
+		if err := cert.WriteFiles(dir, name); err != nil {
+			log.Printf("Failed to write %s certificate: %v", name, err)
+			return
+		}
+		certs[name] = cert
+		fmt.Printf("✓ Issued %s certificate for client@demo.local (serial %s...)\n", name, cert.Cert.SerialNumber.Text(16)[:12])
+	}
+
+	opts, err := server.ProcessConfigFile("config/tls-ocsp.conf")
+	if err != nil {
+		log.Printf("Failed to load config/tls-ocsp.conf: %v", err)
+		return
+	}
+	opts.Port = server.RANDOM_PORT
+
+	s, err := startEmbeddedServer(opts)
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return
+	}
+	defer s.Shutdown()
+	serverURL := s.ClientURL()
+
+	fmt.Println("\n2. Connecting before revocation:")
+	nc, err := nats.Connect(serverURL, certOptions(DefaultTLSDir, dir, "old")...)
+	if err != nil {
+		log.Printf("Connection with the old certificate failed: %v", err)
+		return
+	}
+	fmt.Println("✓ Old certificate accepted")
+
+	fmt.Println("\n3. Revoking the old certificate:")
+	list, err := LoadRevocationList(responder.RevocationFile)
+	if err != nil {
+		log.Printf("Failed to load revocation list: %v", err)
+		return
+	}
+	list.Revoke(certs["old"].Cert, "key compromise drill")
+	if err := list.Save(); err != nil {
+		log.Printf("Failed to save revocation list: %v", err)
+		return
+	}
+	fmt.Println("✓ Old certificate added to the revocation list")
+
+	// OCSP is checked during the TLS handshake, so an established
+	// connection is not affected until it reconnects.
+	if nc.IsConnected() {
+		fmt.Println("  Existing connection stays up until it reconnects")
+	}
+	nc.Close()
+
+	fmt.Println("\n4. Connecting after revocation:")
+	if nc, err := nats.Connect(serverURL, certOptions(DefaultTLSDir, dir, "old")...); err != nil {
+		fmt.Printf("✗ Revoked certificate rejected: %v\n", err)
+	} else {
+		fmt.Println("❌ Revoked certificate was accepted")
+		nc.Close()
+	}
+
+	fresh, err := nats.Connect(serverURL, certOptions(DefaultTLSDir, dir, "fresh")...)
+	if err != nil {
+		log.Printf("Connection with the fresh certificate failed: %v", err)
+		return
+	}
+	defer fresh.Close()
+	fmt.Println("✓ Fresh certificate for the same identity accepted")
+
+	fmt.Println("\n=== TLS Certificate Revocation Demo Complete ===")
+}